@@ -0,0 +1,70 @@
+package bme68x_test
+
+import (
+	"testing"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/emulator"
+)
+
+// configured returns a device configured on the emulated bus, with a short
+// heater duration to keep the measurements fast.
+func configured(tb testing.TB, bus emulator.Bus) *bme68x.Device {
+	tb.Helper()
+
+	d := bus.New(emulator.New(), bme68x.WithHeatrDuration(2))
+	if err := d.Configure(); err != nil {
+		tb.Fatal(err)
+	}
+
+	return d
+}
+
+func TestZeroAllocations(t *testing.T) {
+	for _, bus := range emulator.Buses {
+		t.Run(bus.Name, func(t *testing.T) {
+			d := configured(t, bus)
+
+			for _, c := range []struct {
+				name string
+				runs int
+				do   func() error
+			}{
+				{"Read", 20, d.Read},
+				{"SetTemperatureOversampling", 20, func() error { return d.SetTemperatureOversampling(bme68x.Sampling2X) }},
+				{"SetGasHeater", 20, func() error { return d.SetGasHeater(300, 2, true) }},
+				{"Reset", 2, d.Reset},
+			} {
+				var err error
+
+				allocs := testing.AllocsPerRun(c.runs, func() {
+					if e := c.do(); e != nil {
+						err = e
+					}
+				})
+
+				if err != nil {
+					t.Errorf("%s: %v", c.name, err)
+				} else if allocs != 0 {
+					t.Errorf("%s: %v allocations, want 0", c.name, allocs)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	for _, bus := range emulator.Buses {
+		b.Run(bus.Name, func(b *testing.B) {
+			d := configured(b, bus)
+
+			b.ReportAllocs()
+
+			for b.Loop() {
+				if err := d.Read(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -3,10 +3,15 @@
 //
 // Datasheet:
 // https://www.bosch-sensortec.com/media/boschsensortec/downloads/datasheets/bst-bme680-ds001.pdf
+//
+// The compensation uses floating point by default. Targets without FPU can
+// select the integer compensation with WithIntCompensation, or force it at
+// build time with the bme68x_fixed tag, which leaves the floating point fields
+// of Measurement zero.
 package bme68x
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"math"
 	"time"
@@ -33,6 +38,16 @@ const (
 	WakeUpDur = 1000
 	// MaxDuration is the maximum duration for gas wait
 	MaxDuration = 0xFF
+	// MaxSharedDuration is the shared heater duration in ms above which the
+	// register saturates
+	MaxSharedDuration = 0x783
+	// MaxHeatrSteps is the maximum number of steps in a heater profile
+	MaxHeatrSteps = 10
+	// MaxHeatrTemperature is the maximum heater temperature in deg C
+	MaxHeatrTemperature uint16 = 400
+	// MaxHeatrDuration is the heater duration in ms above which gas wait
+	// saturates
+	MaxHeatrDuration uint16 = 0xFC0
 	// PeriodPoll is thed default period for polling the sensor in µs
 	PeriodPoll uint32 = 10000
 	// PeriodReset is the period for resetting the sensor in µs
@@ -50,45 +65,6 @@ var (
 )
 
 type (
-	// calibrationCoefficients reads at startup and stores the calibration coefficients.
-	calibrationCoefficients struct {
-		// temperature related coefficients
-		t1 uint16
-		t2 int16
-		t3 int8
-
-		// pressure related coefficients
-		p1  uint16
-		p2  int16
-		p3  int8
-		p4  int16
-		p5  int16
-		p6  int8
-		p7  int8
-		p8  int16
-		p9  int16
-		p10 int8
-
-		// humidity related coefficients
-		h1 uint16
-		h2 uint16
-		h3 int8
-		h4 int8
-		h5 int8
-		h6 uint8
-		h7 int8
-
-		// gas related coefficients
-		g1 int8
-		g2 int16
-		g3 int8
-
-		// other coefficients
-		resHeatRange uint8
-		resHeatVal   int8
-		rangeSwErr   int8
-	}
-
 	Config struct {
 		Pressure    Oversampling
 		Temperature Oversampling
@@ -100,50 +76,99 @@ type (
 		// HeatrDur is the gas wait period.
 		HeatrDur uint16
 		// HeatrEnable enables gas measurement.
-		HeatrEnable        bool
+		HeatrEnable bool
+		// HeatrProfile is the heater profile. It is required in parallel and
+		// sequential mode; in forced mode it replaces HeatrTemp and HeatrDur
+		// when set. In parallel mode the duration of each step is a multiple
+		// of HeatrSharedDur, otherwise it is in ms.
+		HeatrProfile []HeaterStep
+		// HeatrSharedDur is the heater duration in ms shared by every step of
+		// the profile in parallel mode.
+		HeatrSharedDur uint16
+		// HeatrStep is the index of the profile step used in forced mode.
+		HeatrStep uint8
+		// HeatrCycle makes Read move to the next profile step after each
+		// measurement in forced mode.
+		HeatrCycle bool
+		// IntCompensation selects the integer compensation, for targets
+		// without FPU. It is always used when built with the bme68x_fixed tag.
+		IntCompensation    bool
 		AmbientTemperature int8
 		PeriodPoll         uint32
 		mode               Mode
 	}
 
-	Device struct {
-		bus                     bus
-		address                 uint16
-		chipID                  byte
-		calibrationCoefficients calibrationCoefficients
-		config                  *Config
-		measStart               int64
-		measPeriod              uint16
+	// HeaterStep is a single step of a heater profile.
+	HeaterStep struct {
+		// Temp is the target temperature in degree Celsius.
+		Temp uint16
+		// Dur is the heating duration. In parallel mode it is a multiplier
+		// of the shared heater duration.
+		Dur uint16
+	}
 
+	// Measurement holds the data of a single field buffer. It is a value
+	// type, safe to share once returned.
+	Measurement struct {
+		// Time is the time the measurement was read.
+		Time time.Time
 		// Status contains new_data, gasm_valid and heat_stab bits.
 		Status byte
-		// GasIndex is the index of the heater profile used.
+		// GasIndex is the index of the heater profile step used.
 		GasIndex uint8
 		// MeasIndex is the measurement index to track order.
 		MeasIndex uint8
 		// ResHeat is the heater resistance.
 		ResHeat uint8
+		// Idac is the current DAC.
+		Idac uint8
 		// GasWait is the gas wait period.
 		GasWait uint8
-		// TemperatureFine is the intermediate temperature coefficient.
+		// TemperatureFine is the intermediate temperature coefficient. It
+		// and the floating point values below are zero when built with the
+		// bme68x_fixed tag.
 		TemperatureFine float32
 		// Temperature is the temperature in degree Celsius.
 		Temperature float32
 		// Pressure is the pressure in Pascal.
 		Pressure float32
-		// Humidity is the relative humidity in percent (x1000).
+		// Humidity is the relative humidity in percent.
 		Humidity float32
 		// GasResistance is the gas resistance in Ohms.
 		GasResistance float32
-		// Idac is the current DAC
-		Idac uint8
+		// TemperatureMilli is the temperature in milli degree Celsius.
+		TemperatureMilli int32
+		// PressurePa is the pressure in Pascal.
+		PressurePa uint32
+		// HumidityMilli is the relative humidity in milli percent.
+		HumidityMilli uint32
+		// GasResistanceOhms is the gas resistance in Ohms.
+		GasResistanceOhms uint32
+	}
+
+	Device struct {
+		bus         bus
+		address     uint16
+		chipID      byte
+		calibration Calibration
+		config      *Config
+		measStart   int64
+		measPeriod  uint16
+		// last is the last measurement read by Read or Update
+		last Measurement
+		// rbuf, wreg and wdata are preallocated for the bus transfers, so that
+		// reading the sensor produces no garbage
+		rbuf  [LEN_COEFF1]byte
+		wreg  [2 * MaxHeatrSteps]uint8
+		wdata [2 * MaxHeatrSteps]byte
+
 		// VariantID is the variant ID.
 		VariantID uint8
 	}
 
 	// bus is the interface for the I2C and SPI bus.
 	bus interface {
-		Reset(addr uint16) error
+		Reset(ctx context.Context, addr uint16) error
 		Read(addr uint16, reg uint8, data []byte) error
 		Write(addr uint16, reg []uint8, data []byte) error
 	}
@@ -160,12 +185,17 @@ func NewI2C(bus drivers.I2C, opts ...Option) *Device {
 }
 
 // NewSPI creates a new BME68x connection. The SPI bus must already be
-// configured. It also requires a CS pin to be used as the chip select.
+// configured. It also requires a CS pin to be used as the chip select,
+// configured as an output.
 //
-// This function only creates the Device object, it does not touch the device.
-func NewSPI(bus drivers.SPI, opts ...Option) *Device {
+// This function only creates the Device object and deselects it, it does not
+// touch the device.
+func NewSPI(bus drivers.SPI, cs Pin, opts ...Option) *Device {
+	cs.High()
+
 	return new(&spi{
 		bus: bus,
+		cs:  cs,
 	}, opts...)
 }
 
@@ -197,16 +227,21 @@ func new(bus bus, opts ...Option) *Device {
 
 // Configure sets up the device for communication.
 func (d *Device) Configure() error {
+	return d.ConfigureContext(context.Background())
+}
+
+// ConfigureContext is like Configure but gives up when the context is done.
+func (d *Device) ConfigureContext(ctx context.Context) error {
 	connected, err := d.Connected()
 	if err != nil {
-		return fmt.Errorf("device not found or not connected: %w", err)
+		return fmt.Errorf("%w: %w", ErrNotConnected, err)
 	}
 
 	if !connected {
-		return errors.New("device not found or not connected")
+		return ErrWrongChipID{ChipID: d.chipID}
 	}
 
-	if err := d.Reset(); err != nil {
+	if err := d.bus.Reset(ctx, d.address); err != nil {
 		return fmt.Errorf("failed to reset device: %w", err)
 	}
 
@@ -222,11 +257,11 @@ func (d *Device) Configure() error {
 		return fmt.Errorf("failed to read calibration data: %w", err)
 	}
 
-	if err := d.applyConfig(); err != nil {
+	if err := d.applyConfig(ctx); err != nil {
 		return fmt.Errorf("failed to apply config: %w", err)
 	}
 
-	if err := d.applyGasConfig(); err != nil {
+	if err := d.applyGasConfig(ctx); err != nil {
 		return fmt.Errorf("failed to apply gas config: %w", err)
 	}
 
@@ -234,79 +269,81 @@ func (d *Device) Configure() error {
 }
 
 func (d *Device) readChipID() error {
-	var data [1]byte
-	if err := d.bus.Read(d.address, REG_CHIP_ID, data[:]); err != nil {
+	chipID, err := d.readReg(REG_CHIP_ID)
+	if err != nil {
 		return err
 	}
-	d.chipID = data[0]
+	d.chipID = chipID
 	return nil
 }
 
 func (d *Device) readVariantID() error {
-	var data [1]byte
-	if err := d.bus.Read(d.address, REG_VARIANT_ID, data[:]); err != nil {
+	variantID, err := d.readReg(REG_VARIANT_ID)
+	if err != nil {
 		return err
 	}
-	d.VariantID = data[0]
+	d.VariantID = variantID
 	return nil
 }
 
 func (d *Device) readCalibrationData() error {
-	var data [42]byte
+	var data [LEN_COEFF_ALL]byte
 
 	// read the calibration data
-	if err := d.bus.Read(d.address, REG_COEFF1, data[:23]); err != nil {
+	coeff, err := d.read(REG_COEFF1, LEN_COEFF1)
+	if err != nil {
 		return err
 	}
-	if err := d.bus.Read(d.address, REG_COEFF2, data[23:37]); err != nil {
+	copy(data[:], coeff)
+
+	if coeff, err = d.read(REG_COEFF2, LEN_COEFF2); err != nil {
 		return err
 	}
-	if err := d.bus.Read(d.address, REG_COEFF3, data[37:]); err != nil {
+	copy(data[LEN_COEFF1:], coeff)
+
+	if coeff, err = d.read(REG_COEFF3, LEN_COEFF3); err != nil {
 		return err
 	}
+	copy(data[LEN_COEFF1+LEN_COEFF2:], coeff)
 
-	// temperature related coefficients
-	d.calibrationCoefficients.t1 = parseByte[uint16](data[32], data[31])
-	d.calibrationCoefficients.t2 = parseByte[int16](data[1], data[0])
-	d.calibrationCoefficients.t3 = int8(data[2])
-
-	// pressure related coefficients
-	d.calibrationCoefficients.p1 = parseByte[uint16](data[5], data[4])
-	d.calibrationCoefficients.p2 = parseByte[int16](data[7], data[6])
-	d.calibrationCoefficients.p3 = int8(data[8])
-	d.calibrationCoefficients.p4 = parseByte[int16](data[11], data[10])
-	d.calibrationCoefficients.p5 = parseByte[int16](data[13], data[12])
-	d.calibrationCoefficients.p6 = int8(data[15])
-	d.calibrationCoefficients.p7 = int8(data[14])
-	d.calibrationCoefficients.p8 = parseByte[int16](data[19], data[18])
-	d.calibrationCoefficients.p9 = parseByte[int16](data[21], data[20])
-	d.calibrationCoefficients.p10 = int8(data[22])
-
-	// humidity related coefficients
-	d.calibrationCoefficients.h1 = uint16(data[25])<<4 | uint16(data[24])&0x0F
-	d.calibrationCoefficients.h2 = uint16(data[23])<<4 | uint16(data[24])>>4
-	d.calibrationCoefficients.h3 = int8(data[26])
-	d.calibrationCoefficients.h4 = int8(data[27])
-	d.calibrationCoefficients.h5 = int8(data[28])
-	d.calibrationCoefficients.h6 = data[29]
-	d.calibrationCoefficients.h7 = int8(data[30])
-
-	// gas heater related coefficients
-	d.calibrationCoefficients.g1 = int8(data[35])
-	d.calibrationCoefficients.g2 = parseByte[int16](data[34], data[33])
-	d.calibrationCoefficients.g3 = int8(data[36])
-
-	// other coefficients
-	d.calibrationCoefficients.resHeatRange = (data[39] & 0x30) / 16
-	d.calibrationCoefficients.resHeatVal = int8(data[37])
-	d.calibrationCoefficients.rangeSwErr = int8(data[41]&0xF0) / 16
+	d.calibration = parseCalibration(data)
+	d.calibration.VariantID = d.VariantID
 
 	return nil
 }
 
+// read reads n consecutive registers starting at reg. The returned slice is
+// a preallocated buffer, only valid until the next read.
+func (d *Device) read(reg uint8, n uint8) ([]byte, error) {
+	data := d.rbuf[:n]
+	if err := d.bus.Read(d.address, reg, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// readReg reads a single register.
+func (d *Device) readReg(reg uint8) (byte, error) {
+	data, err := d.read(reg, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return data[0], nil
+}
+
+// writeReg writes a single register, using the start of the write buffers.
+func (d *Device) writeReg(reg, value uint8) error {
+	d.wreg[0] = reg
+	d.wdata[0] = value
+
+	return d.bus.Write(d.address, d.wreg[:1], d.wdata[:1])
+}
+
 // Reset does a soft reset by writing 0xB6 to the reset register.
 func (d *Device) Reset() error {
-	return d.bus.Reset(d.address)
+	return d.bus.Reset(context.Background(), d.address)
 }
 
 // Connected checks if the device is connected by reading the chip ID.
@@ -319,49 +356,74 @@ func (d *Device) Connected() (bool, error) {
 	return d.chipID == CHIP_ID, nil
 }
 
-// Mode returns the current mode of the sensor.
+// Mode returns the current power mode of the sensor.
 func (d *Device) Mode() (Mode, error) {
-	var data [1]byte
-	if err := d.bus.Read(d.address, REG_CTRL_MEAS, data[:]); err != nil {
+	ctrlMeas, err := d.readReg(REG_CTRL_MEAS)
+	if err != nil {
 		return ModeSleep, err
 	}
 
-	d.config.mode = Mode(data[0] & MODE_MSK)
-
-	return d.config.mode, nil
+	return regCtrlMeas(ctrlMeas).Mode(), nil
 }
 
-// SetMode sets the mode of the sensor.
+// SetMode sets the mode of the sensor. Any mode other than ModeSleep also
+// becomes the operation mode used by Read; switching the operation mode
+// rewrites the heater configuration for the new mode.
 func (d *Device) SetMode(mode Mode) error {
-	d.config.mode = mode
+	return d.SetModeContext(context.Background(), mode)
+}
 
-	var (
-		tmpPowerMode [1]byte
-		powerMode    byte
-	)
+// SetModeContext is like SetMode but gives up waiting for the sensor to sleep
+// when the context is done.
+func (d *Device) SetModeContext(ctx context.Context, mode Mode) error {
+	if mode != ModeSleep && mode != d.config.mode {
+		d.config.mode = mode
+
+		if err := d.applyGasConfig(ctx); err != nil {
+			return fmt.Errorf("failed to apply gas config: %w", err)
+		}
+	}
 
-	for ok := true; ok; ok = (powerMode != byte(ModeSleep)) {
+	if err := d.setPowerMode(ctx, mode); err != nil {
+		return fmt.Errorf("failed to set mode: %w", err)
+	}
+
+	return nil
+}
+
+// setPowerMode writes the power mode, going through sleep mode first. It
+// polls until the sensor sleeps or the context is done.
+func (d *Device) setPowerMode(ctx context.Context, mode Mode) error {
+	var ctrlMeas regCtrlMeas
+
+	for ok := true; ok; ok = (ctrlMeas.Mode() != ModeSleep) {
 		// read the current power mode
-		if err := d.bus.Read(d.address, REG_CTRL_MEAS, tmpPowerMode[:]); err != nil {
+		value, err := d.readReg(REG_CTRL_MEAS)
+		if err != nil {
 			return err
 		}
 
+		ctrlMeas = regCtrlMeas(value)
+
 		// put to sleep before changing mode
-		powerMode = (tmpPowerMode[0] & MODE_MSK)
-		if powerMode != byte(ModeSleep) {
-			tmpPowerMode[0] &= ^MODE_MSK
-			if err := d.bus.Write(d.address, []uint8{REG_CTRL_MEAS}, tmpPowerMode[:]); err != nil {
+		if ctrlMeas.Mode() != ModeSleep {
+			sleeping := ctrlMeas
+			sleeping.SetMode(ModeSleep)
+
+			if err := d.writeReg(REG_CTRL_MEAS, uint8(sleeping)); err != nil {
 				return err
 			}
 
-			time.Sleep(time.Duration(d.config.PeriodPoll) * time.Microsecond)
+			if err := sleep(ctx, time.Duration(d.config.PeriodPoll)*time.Microsecond); err != nil {
+				return err
+			}
 		}
 	}
 
 	// already in sleep
 	if mode != ModeSleep {
-		tmpPowerMode[0] = (tmpPowerMode[0] & ^MODE_MSK) | (byte(mode) & MODE_MSK)
-		if err := d.bus.Write(d.address, []uint8{REG_CTRL_MEAS}, tmpPowerMode[:]); err != nil {
+		ctrlMeas.SetMode(mode)
+		if err := d.writeReg(REG_CTRL_MEAS, uint8(ctrlMeas)); err != nil {
 			return err
 		}
 	}
@@ -373,7 +435,7 @@ func (d *Device) SetMode(mode Mode) error {
 func (d *Device) SetTemperatureOversampling(os Oversampling) error {
 	d.config.Temperature = os
 
-	if err := d.applyConfig(); err != nil {
+	if err := d.applyConfig(context.Background()); err != nil {
 		return fmt.Errorf("failed to apply config: %w", err)
 	}
 
@@ -384,7 +446,7 @@ func (d *Device) SetTemperatureOversampling(os Oversampling) error {
 func (d *Device) SetPressureOversampling(os Oversampling) error {
 	d.config.Pressure = os
 
-	if err := d.applyConfig(); err != nil {
+	if err := d.applyConfig(context.Background()); err != nil {
 		return fmt.Errorf("failed to apply config: %w", err)
 	}
 
@@ -395,7 +457,7 @@ func (d *Device) SetPressureOversampling(os Oversampling) error {
 func (d *Device) SetHumidityOversampling(os Oversampling) error {
 	d.config.Humidity = os
 
-	if err := d.applyConfig(); err != nil {
+	if err := d.applyConfig(context.Background()); err != nil {
 		return fmt.Errorf("failed to apply config: %w", err)
 	}
 
@@ -406,7 +468,7 @@ func (d *Device) SetHumidityOversampling(os Oversampling) error {
 func (d *Device) SetIIRFilter(fc FilterCoefficient) error {
 	d.config.IIR = fc
 
-	if err := d.applyConfig(); err != nil {
+	if err := d.applyConfig(context.Background()); err != nil {
 		return fmt.Errorf("failed to apply config: %w", err)
 	}
 
@@ -417,7 +479,7 @@ func (d *Device) SetIIRFilter(fc FilterCoefficient) error {
 func (d *Device) SetODR(odr ODR) error {
 	d.config.ODR = odr
 
-	if err := d.applyConfig(); err != nil {
+	if err := d.applyConfig(context.Background()); err != nil {
 		return fmt.Errorf("failed to apply config: %w", err)
 	}
 
@@ -429,261 +491,649 @@ func (d *Device) SetGasHeater(temp, dur uint16, enable bool) error {
 	d.config.HeatrDur = dur
 	d.config.HeatrEnable = enable
 
-	if err := d.applyGasConfig(); err != nil {
+	if err := d.applyGasConfig(context.Background()); err != nil {
 		return fmt.Errorf("failed to apply gas config: %w", err)
 	}
 
 	return nil
 }
 
+// SetHeaterProfile sets the heater profile. In parallel mode each step
+// duration is a multiplier of sharedDur, given in ms, which is ignored in the
+// other modes.
+func (d *Device) SetHeaterProfile(profile []HeaterStep, sharedDur uint16) error {
+	d.config.HeatrProfile = append(d.config.HeatrProfile[:0], profile...)
+	d.config.HeatrSharedDur = sharedDur
+	d.config.HeatrStep = 0
+
+	if err := d.applyGasConfig(context.Background()); err != nil {
+		return fmt.Errorf("failed to apply gas config: %w", err)
+	}
+
+	return nil
+}
+
+// SelectHeaterStep selects the heater profile step used by the next
+// measurement in forced mode.
+func (d *Device) SelectHeaterStep(step uint8) error {
+	return d.selectHeaterStep(context.Background(), step)
+}
+
+func (d *Device) selectHeaterStep(ctx context.Context, step uint8) error {
+	if int(step) >= len(d.config.HeatrProfile) {
+		return fmt.Errorf("%w: invalid heater step: %d", ErrInvalidConfig, step)
+	}
+
+	d.config.HeatrStep = step
+
+	// configure only in the sleep mode
+	if err := d.setPowerMode(ctx, ModeSleep); err != nil {
+		return err
+	}
+
+	return updateRegister(d, func(ctrlGas1 *regCtrlGas1) {
+		ctrlGas1.SetNbConv(step)
+	})
+}
+
 // applyConfig sets oversampling and filter configuration.
-func (d *Device) applyConfig() error {
+func (d *Device) applyConfig(ctx context.Context) error {
 	currentMode, err := d.Mode()
 	if err != nil {
 		return err
 	}
 
 	// configure only in the sleep mode
-	if err := d.SetMode(ModeSleep); err != nil {
+	if err := d.setPowerMode(ctx, ModeSleep); err != nil {
 		return err
 	}
 
-	// read the current configuration
-	var data [5]byte
-	if err := d.bus.Read(d.address, REG_CTRL_GAS_1, data[:]); err != nil {
+	if err := d.writeConfig(); err != nil {
 		return err
 	}
 
-	// set bits
-	data[4] = (data[4] & ^FILTER_MSK) | ((byte(d.config.IIR) << FILTER_POS) & FILTER_MSK)
-	data[3] = (data[3] & ^OST_MSK) | ((byte(d.config.Temperature) << OST_POS) & OST_MSK)
-	data[3] = (data[3] & ^OSP_MSK) | ((byte(d.config.Pressure) << OSP_POS) & OSP_MSK)
-	data[1] = (data[1] & ^OSH_MSK) | (byte(d.config.Humidity) & OSH_MSK)
+	// restore the previous mode
+	if currentMode != ModeSleep {
+		if err := d.setPowerMode(ctx, currentMode); err != nil {
+			return err
+		}
+	}
 
-	var odr20 ODR
-	odr3 := 1
+	return nil
+}
 
-	if d.config.ODR != ODR_NONE {
-		odr20 = d.config.ODR
-		odr3 = 0
+// writeConfig writes the oversampling, filter and ODR configuration. The
+// sensor must be in sleep mode.
+func (d *Device) writeConfig() error {
+	// read the current configuration
+	ctrl, err := d.read(REG_CTRL_GAS_1, 5)
+	if err != nil {
+		return err
 	}
 
-	data[4] = (data[4] & ^ODR20_MSK) | ((byte(odr20) << ODR20_POS) & ODR20_MSK)
-	data[0] = (data[0] & ^ODR3_MSK) | ((byte(odr3) << ODR3_POS) & ODR3_MSK)
+	data := d.wdata[:5]
+	copy(data, ctrl)
+
+	d.setConfigFields(data)
 
 	// write the new configuration
 	// register data starting from REG_CTRL_GAS_1(0x71) up to REG_CONFIG(0x75)
-	if err := d.bus.Write(
-		d.address,
-		[]uint8{REG_CTRL_GAS_1, REG_CTRL_HUM, 0x73, REG_CTRL_MEAS, REG_CONFIG},
-		data[:],
-	); err != nil {
+	reg := d.wreg[:5]
+	reg[0], reg[1], reg[2], reg[3], reg[4] = REG_CTRL_GAS_1, REG_CTRL_HUM, 0x73, REG_CTRL_MEAS, REG_CONFIG
+
+	if err := d.bus.Write(d.address, reg, data); err != nil {
 		return err
 	}
 
-	// restore the previous mode
-	if currentMode != ModeSleep {
-		if err := d.SetMode(currentMode); err != nil {
-			return err
-		}
+	return nil
+}
+
+// setConfigFields sets the oversampling, filter and ODR fields in data, the
+// registers from REG_CTRL_GAS_1 to REG_CONFIG.
+func (d *Device) setConfigFields(data []byte) {
+	var (
+		ctrlGas1 = (*regCtrlGas1)(&data[0])
+		ctrlHum  = (*regCtrlHum)(&data[1])
+		ctrlMeas = (*regCtrlMeas)(&data[3])
+		config   = (*regConfig)(&data[4])
+	)
+
+	config.SetFilter(d.config.IIR)
+	ctrlMeas.SetOST(d.config.Temperature)
+	ctrlMeas.SetOSP(d.config.Pressure)
+	ctrlHum.SetOSH(d.config.Humidity)
+
+	var odr20 ODR
+	odr3 := true
+
+	if d.config.ODR != ODR_NONE {
+		odr20 = d.config.ODR
+		odr3 = false
 	}
 
-	return nil
+	config.SetODR20(odr20)
+	ctrlGas1.SetODR3(odr3)
 }
 
 // applyGasConfig sets the gas configuration of the sensor.
-func (d *Device) applyGasConfig() error {
+func (d *Device) applyGasConfig(ctx context.Context) error {
+	// configure only in the sleep mode
+	if err := d.setPowerMode(ctx, ModeSleep); err != nil {
+		return err
+	}
+
+	return d.writeGasConfig()
+}
+
+// writeGasConfig writes the heater and gas configuration. The sensor must be
+// in sleep mode.
+func (d *Device) writeGasConfig() error {
 	if d.config.HeatrTemp == 0 || d.config.HeatrDur == 0 {
 		d.config.HeatrEnable = false
 	}
 
-	// configure only in the sleep mode
-	if err := d.SetMode(ModeSleep); err != nil {
+	nbConv, err := d.applyHeatrConfig()
+	if err != nil {
 		return err
 	}
 
-	if err := d.applyHeatrConfig(); err != nil {
+	// read the current configuration
+	ctrlGas, err := d.read(REG_CTRL_GAS_0, 2)
+	if err != nil {
 		return err
 	}
 
-	var hctrl, runGas byte
-	var ctrlGasData [2]byte
-	var nbConv byte = 0
+	ctrlGasData := d.wdata[:2]
+	copy(ctrlGasData, ctrlGas)
 
-	// read the current configuration
-	if err := d.bus.Read(d.address, REG_CTRL_GAS_0, ctrlGasData[:]); err != nil {
+	d.setGasFields(ctrlGasData, nbConv)
+
+	// write the new configuration
+	reg := d.wreg[:2]
+	reg[0], reg[1] = REG_CTRL_GAS_0, REG_CTRL_GAS_1
+
+	if err := d.bus.Write(d.address, reg, ctrlGasData); err != nil {
 		return err
 	}
 
-	if d.config.HeatrEnable {
-		hctrl = ENABLE_HEATER
+	return nil
+}
 
+// setGasFields sets the heater and gas fields in data, the REG_CTRL_GAS_0 and
+// REG_CTRL_GAS_1 registers.
+func (d *Device) setGasFields(data []byte, nbConv uint8) {
+	var (
+		heatOff bool
+		runGas  byte
+	)
+
+	if d.config.HeatrEnable {
 		if d.VariantID == VARIANT_GAS_HIGH {
 			runGas = ENABLE_GAS_MEAS_H
 		} else {
 			runGas = ENABLE_GAS_MEAS_L
 		}
 	} else {
-		hctrl = DISABLE_HEATER
+		heatOff = true
 		runGas = DISABLE_GAS_MEAS
 	}
 
-	ctrlGasData[0] = (ctrlGasData[0] & ^HCTRL_MSK) | ((hctrl << HCTRL_POS) & HCTRL_MSK)
-	ctrlGasData[1] = (ctrlGasData[1] & ^NBCONV_MSK) | (nbConv & NBCONV_MSK)
-	ctrlGasData[1] = (ctrlGasData[1] & ^RUN_GAS_MSK) | ((runGas << RUN_GAS_POS) & RUN_GAS_MSK)
+	ctrlGas0 := (*regCtrlGas0)(&data[0])
+	ctrlGas0.SetHeatOff(heatOff)
+
+	ctrlGas1 := (*regCtrlGas1)(&data[1])
+	ctrlGas1.SetNbConv(nbConv)
+	ctrlGas1.SetRunGas(runGas)
+}
+
+// applyHeatrConfig sets the heater configurations for the operation mode. It
+// returns the value to be written to nb_conv.
+func (d *Device) applyHeatrConfig() (uint8, error) {
+	var (
+		rhRegAddr, gwRegAddr = d.wreg[:MaxHeatrSteps], d.wreg[MaxHeatrSteps:]
+		rhRegData, gwRegData = d.wdata[:MaxHeatrSteps], d.wdata[MaxHeatrSteps:]
+	)
+
+	writeLen, nbConv, err := d.heatrRegisters(rhRegData, gwRegData)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := uint8(0); i < writeLen; i++ {
+		rhRegAddr[i] = REG_RES_HEAT0 + i
+		gwRegAddr[i] = REG_GAS_WAIT0 + i
+	}
 
 	// write the new configuration
-	if err := d.bus.Write(d.address, []uint8{REG_CTRL_GAS_0, REG_CTRL_GAS_1}, ctrlGasData[:]); err != nil {
-		return err
+	if err := d.bus.Write(d.address, rhRegAddr[:writeLen], rhRegData[:writeLen]); err != nil {
+		return 0, err
 	}
 
-	return nil
+	if err := d.bus.Write(d.address, gwRegAddr[:writeLen], gwRegData[:writeLen]); err != nil {
+		return 0, err
+	}
+
+	// write it last, as writeReg uses the start of the buffers
+	if d.config.mode == ModeParallel {
+		if err := d.writeReg(REG_SHD_HEATR_DUR, calcHeatrDurShared(d.config.HeatrSharedDur)); err != nil {
+			return 0, err
+		}
+	}
+
+	return nbConv, nil
 }
 
-// applyHeatrConfig sets the heater configurations.
-func (d *Device) applyHeatrConfig() error {
-	rhRegData := make([]uint8, 1)
-	gwRegData := make([]uint8, 1)
+// heatrRegisters fills the res_heat_x and gas_wait_x values for the operation
+// mode. It returns the number of heater steps and the value of nb_conv.
+func (d *Device) heatrRegisters(resHeat, gasWait []uint8) (uint8, uint8, error) {
+	switch {
+	case d.config.mode == ModeParallel:
+		if len(d.config.HeatrProfile) == 0 || len(d.config.HeatrProfile) > MaxHeatrSteps {
+			return 0, 0, fmt.Errorf("%w: invalid heater profile length: %d", ErrInvalidConfig, len(d.config.HeatrProfile))
+		}
 
-	rhRegData[0] = d.calcResistanceHeat(d.config.HeatrTemp)
-	gwRegData[0] = d.calcGasWait()
+		if d.config.HeatrSharedDur == 0 {
+			return 0, 0, fmt.Errorf("%w: shared heater duration not defined", ErrInvalidConfig)
+		}
 
-	// write the new configuration
-	if err := d.bus.Write(d.address, []uint8{REG_RES_HEAT0}, rhRegData[:]); err != nil {
-		return err
+		for i, step := range d.config.HeatrProfile {
+			resHeat[i] = d.resistanceHeat(step.Temp)
+			// in parallel mode gas_wait_x holds a multiplier of the shared duration
+			gasWait[i] = uint8(step.Dur)
+		}
+
+		n := uint8(len(d.config.HeatrProfile))
+
+		return n, n, nil
+	case d.config.mode == ModeSequential || len(d.config.HeatrProfile) > 0:
+		if len(d.config.HeatrProfile) == 0 || len(d.config.HeatrProfile) > MaxHeatrSteps {
+			return 0, 0, fmt.Errorf("%w: invalid heater profile length: %d", ErrInvalidConfig, len(d.config.HeatrProfile))
+		}
+
+		for i, step := range d.config.HeatrProfile {
+			resHeat[i] = d.resistanceHeat(step.Temp)
+			gasWait[i] = d.calcGasWait(step.Dur)
+		}
+
+		n := uint8(len(d.config.HeatrProfile))
+
+		if d.config.mode == ModeSequential {
+			return n, n, nil
+		}
+
+		// in forced mode nb_conv selects the heater step
+		if d.config.HeatrStep >= n {
+			return 0, 0, fmt.Errorf("%w: invalid heater step: %d", ErrInvalidConfig, d.config.HeatrStep)
+		}
+
+		return n, d.config.HeatrStep, nil
+	default:
+		resHeat[0] = d.resistanceHeat(d.config.HeatrTemp)
+		gasWait[0] = d.calcGasWait(d.config.HeatrDur)
+
+		return 1, 0, nil
 	}
+}
 
-	if err := d.bus.Write(d.address, []uint8{REG_GAS_WAIT0}, gwRegData[:]); err != nil {
+// Read reads all sensor data and store it in the Device struct.
+func (d *Device) Read() error {
+	m, err := d.Measure()
+	if err != nil {
 		return err
 	}
 
+	d.setMeasurement(m)
+
 	return nil
 }
 
-// Read reads all sensor data and store it in the Device struct.
-func (d *Device) Read() error {
-	if d.measStart != 0 {
-		return nil
+// Measure triggers a measurement and returns it without touching the Device
+// fields. It waits for a measurement already started with StartMeasurement.
+// It returns ErrNoNewData if no measurement was available in time.
+func (d *Device) Measure() (Measurement, error) {
+	return d.MeasureContext(context.Background())
+}
+
+// MeasureContext is like Measure but stops waiting when the context is done.
+// A measurement interrupted while in progress is resumed by the next call.
+func (d *Device) MeasureContext(ctx context.Context) (Measurement, error) {
+	raw, err := d.MeasureRawContext(ctx)
+	if err != nil {
+		return Measurement{}, err
+	}
+
+	return d.compensate(raw), nil
+}
+
+// MeasureRaw is like Measure but returns the uncompensated ADC values, to be
+// compensated later with Compensate.
+func (d *Device) MeasureRaw() (Raw, error) {
+	return d.MeasureRawContext(context.Background())
+}
+
+// MeasureRawContext is like MeasureRaw but stops waiting when the context is
+// done.
+func (d *Device) MeasureRawContext(ctx context.Context) (Raw, error) {
+	if d.measStart == 0 {
+		if err := d.startMeasurement(ctx); err != nil {
+			return Raw{}, err
+		}
+	}
+
+	if err := sleep(ctx, d.Remaining()); err != nil {
+		return Raw{}, fmt.Errorf("failed to wait for measurement: %w", err)
+	}
+
+	raw, err := d.readRawData(ctx)
+	if err != nil {
+		// start a new measurement with the next call
+		d.measStart = 0
+		d.measPeriod = 0
+
+		return Raw{}, fmt.Errorf("failed to read data: %w", err)
 	}
 
-	if err := d.SetMode(ModeForced); err != nil {
-		return fmt.Errorf("failed to set forced mode: %w", err)
+	if err := d.endMeasurement(ctx); err != nil {
+		return Raw{}, err
+	}
+
+	return raw, nil
+}
+
+// StartMeasurement starts a measurement and returns without waiting for it.
+// In forced mode a single measurement is triggered, in parallel and
+// sequential mode the sensor is woken up if needed. Use Remaining or Ready to
+// know when the result can be read with Fetch.
+func (d *Device) StartMeasurement() error {
+	return d.startMeasurement(context.Background())
+}
+
+func (d *Device) startMeasurement(ctx context.Context) error {
+	switch d.config.mode {
+	case ModeParallel, ModeSequential:
+		// the sensor measures continuously, only wake it up if needed
+		currentMode, err := d.Mode()
+		if err != nil {
+			return fmt.Errorf("failed to read mode: %w", err)
+		}
+
+		if currentMode != d.config.mode {
+			if err := d.setPowerMode(ctx, d.config.mode); err != nil {
+				return fmt.Errorf("failed to set mode: %w", err)
+			}
+		}
+	default:
+		if err := d.setPowerMode(ctx, ModeForced); err != nil {
+			return fmt.Errorf("failed to set forced mode: %w", err)
+		}
 	}
 
 	// calculate delay period in microseconds
-	delayusPeriod := d.calcMeasDuration() + (uint32(d.config.HeatrDur) * 1000)
+	delayusPeriod := d.config.measDuration() + (uint32(d.heatrDuration()) * 1000)
 	d.measStart = time.Now().UnixMilli()
-	d.measPeriod = uint16(delayusPeriod) / 1000
+	d.measPeriod = uint16(delayusPeriod / 1000)
 
-	if d.measStart+int64(d.measPeriod) == 0 {
-		return nil
-	}
+	return nil
+}
 
+// Remaining returns the time left before the started measurement completes.
+// It returns 0 when no measurement is in progress.
+func (d *Device) Remaining() time.Duration {
 	remainingMillis := d.calRemainingReadingMillis()
-	if remainingMillis > 0 {
-		time.Sleep(time.Duration(remainingMillis*2) * time.Millisecond)
+	if remainingMillis < 0 {
+		return 0
 	}
 
+	return time.Duration(remainingMillis) * time.Millisecond
+}
+
+// Ready reports whether a field buffer holds new data. The sensor is not
+// accessed before the measurement period has elapsed.
+func (d *Device) Ready() (bool, error) {
+	if d.calRemainingReadingMillis() > 0 {
+		return false, nil
+	}
+
+	for i := uint8(0); i < N_FIELDS; i++ {
+		status, err := d.readReg(MEAS_STATUS_0 + (i * LEN_FIELD))
+		if err != nil {
+			return false, err
+		}
+
+		if status&NEW_DATA_MSK != 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Fetch returns the most recent measurement without waiting. It returns
+// ErrNoNewData if the sensor has none yet, in which case the measurement stays
+// in progress.
+func (d *Device) Fetch() (Measurement, error) {
+	var fields [N_FIELDS]Measurement
+
+	n, err := d.readFields(&fields)
+	if err != nil {
+		return Measurement{}, fmt.Errorf("failed to read data: %w", err)
+	}
+
+	if n == 0 {
+		return Measurement{}, ErrNoNewData
+	}
+
+	if err := d.endMeasurement(context.Background()); err != nil {
+		return Measurement{}, err
+	}
+
+	return fields[n-1], nil
+}
+
+// endMeasurement clears the measurement bookkeeping and moves to the next
+// heater step when cycling in forced mode.
+func (d *Device) endMeasurement(ctx context.Context) error {
 	d.measStart = 0
 	d.measPeriod = 0
 
-	if err := d.readData(); err != nil {
-		return fmt.Errorf("failed to read data: %w", err)
+	if d.config.mode == ModeForced && d.config.HeatrCycle && len(d.config.HeatrProfile) > 0 {
+		step := (d.config.HeatrStep + 1) % uint8(len(d.config.HeatrProfile))
+		if err := d.selectHeaterStep(ctx, step); err != nil {
+			return fmt.Errorf("failed to select heater step: %w", err)
+		}
 	}
 
 	return nil
 }
 
-func (d *Device) readData() error {
+// readRawData returns the most recent raw measurement holding new data, or
+// ErrNoNewData if none is available after polling.
+func (d *Device) readRawData(ctx context.Context) (Raw, error) {
+	var fields [N_FIELDS]Raw
+
 	// try up to 5 times to read the data
-	for i := uint8(0); i < 5; i++ {
-		var data [17]byte
-		if err := d.bus.Read(d.address, MEAS_STATUS_0+(i*17), data[:]); err != nil {
-			return err
+	for i := 0; i < 5; i++ {
+		n, err := d.readRawFields(&fields)
+		if err != nil {
+			return Raw{}, err
 		}
 
-		d.Status = data[0] & NEW_DATA_MSK
-		d.GasIndex = data[0] & GAS_INDEX_MSK
-		d.MeasIndex = data[1]
-
-		// read the raw data from the sensor
-		adcPres := uint32((uint32(data[2]) * 4096) | (uint32(data[3]) * 16) | (uint32(data[4]) / 16))
-		adcTemp := uint32((uint32(data[5]) * 4096) | (uint32(data[6]) * 16) | (uint32(data[7]) / 16))
-		adcHum := uint16((uint32(data[8]) * 256) | (uint32(data[9])))
-		adcGasResLow := uint16(uint32(data[13])*4 | (uint32(data[14]) / 64))
-		adcGasResHigh := uint16(uint32(data[15])*4 | (uint32(data[16]) / 64))
-		gasRangeLow := data[14] & GAS_RANGE_MSK
-		gasRangeHigh := data[16] & GAS_RANGE_MSK
+		if n > 0 {
+			return fields[n-1], nil
+		}
 
-		if d.VariantID == VARIANT_GAS_HIGH {
-			d.Status |= data[16] & GASM_VALID_MSK
-			d.Status |= data[16] & HEAT_STAB_MSK
-		} else {
-			d.Status |= data[14] & GASM_VALID_MSK
-			d.Status |= data[14] & HEAT_STAB_MSK
+		if err := sleep(ctx, time.Duration(d.config.PeriodPoll)*time.Microsecond); err != nil {
+			return Raw{}, err
 		}
+	}
 
-		// check if new data is available
-		if d.Status&NEW_DATA_MSK != 0 {
-			var resHeat [1]byte
-			if err := d.bus.Read(d.address, REG_RES_HEAT0+d.GasIndex, resHeat[:]); err != nil {
-				return err
-			}
-			d.ResHeat = resHeat[0]
+	return Raw{}, ErrNoNewData
+}
 
-			var idac [1]byte
-			if err := d.bus.Read(d.address, REG_IDAC_HEAT0+d.GasIndex, idac[:]); err != nil {
-				return err
-			}
-			d.Idac = idac[0]
+// ReadFields reads the three field buffers of the sensor. It returns the
+// fields holding new data, ordered by measurement index and without
+// duplicates.
+func (d *Device) ReadFields() ([]Measurement, error) {
+	var fields [N_FIELDS]Measurement
 
-			var gasWait [1]byte
-			if err := d.bus.Read(d.address, REG_GAS_WAIT0+d.GasIndex, gasWait[:]); err != nil {
-				return err
-			}
-			d.GasWait = gasWait[0]
-
-			d.Temperature = d.calcTemperature(adcTemp)
-			d.Pressure = d.calcPressure(adcPres)
-			d.Humidity = d.calcHumidity(adcHum)
-
-			// check if gas data is available
-			if d.Status&(HEAT_STAB_MSK|GASM_VALID_MSK) != 0 {
-				if d.VariantID == VARIANT_GAS_HIGH {
-					d.GasResistance = d.calcGasResistanceHigh(adcGasResHigh, gasRangeHigh)
-				} else {
-					d.GasResistance = d.calcGasResistanceLow(adcGasResLow, gasRangeLow)
-				}
-			} else {
-				d.GasResistance = 0
-			}
+	n, err := d.readFields(&fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]Measurement(nil), fields[:n]...), nil
+}
+
+// readFields reads and compensates the three field buffers into fields, see
+// readRawFields.
+func (d *Device) readFields(fields *[N_FIELDS]Measurement) (int, error) {
+	var raws [N_FIELDS]Raw
 
-			break
+	n, err := d.readRawFields(&raws)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < n; i++ {
+		fields[i] = d.compensate(raws[i])
+	}
+
+	return n, nil
+}
+
+// readRawFields reads the three field buffers into fields and returns the
+// number of fields holding new data. These are moved to the front of fields,
+// ordered by measurement index and without duplicates.
+func (d *Device) readRawFields(fields *[N_FIELDS]Raw) (int, error) {
+	n := 0
+
+	for i := uint8(0); i < N_FIELDS; i++ {
+		raw, err := d.readRawField(i)
+		if err != nil {
+			return 0, err
+		}
+
+		// drop stale fields
+		if !raw.NewData() {
+			continue
+		}
+
+		// insert ordered by measurement index, the index wraps around
+		j := n
+		for j > 0 && int8(raw.MeasIndex-fields[j-1].MeasIndex) < 0 {
+			j--
+		}
+
+		// drop duplicates
+		if j > 0 && fields[j-1].MeasIndex == raw.MeasIndex {
+			continue
 		}
 
-		time.Sleep(time.Duration(d.config.PeriodPoll) * time.Microsecond)
+		copy(fields[j+1:n+1], fields[j:n])
+		fields[j] = raw
+		n++
 	}
 
-	return nil
+	return n, nil
 }
 
-func (d *Device) calcTemperature(adcTemp uint32) float32 {
-	var1 := (((float32(adcTemp) / 16384) - (float32(d.calibrationCoefficients.t1) / 1024)) * float32(d.calibrationCoefficients.t2))
-	var2 := ((((float32(adcTemp) / 131072) - (float32(d.calibrationCoefficients.t1) / 8192)) *
-		((float32(adcTemp) / 131072) - (float32(d.calibrationCoefficients.t1) / 8192))) * (float32(d.calibrationCoefficients.t3) * 16))
+// readRawField reads the field buffer at index. The heater registers are only
+// read when the field holds new data.
+func (d *Device) readRawField(index uint8) (Raw, error) {
+	data, err := d.read(MEAS_STATUS_0+(index*LEN_FIELD), LEN_FIELD)
+	if err != nil {
+		return Raw{}, err
+	}
+
+	raw := parseRaw([LEN_FIELD]byte(data), d.VariantID)
+
+	// check if new data is available
+	if !raw.NewData() {
+		return raw, nil
+	}
+
+	if raw.ResHeat, err = d.readReg(REG_RES_HEAT0 + raw.GasIndex); err != nil {
+		return raw, err
+	}
+
+	if raw.Idac, err = d.readReg(REG_IDAC_HEAT0 + raw.GasIndex); err != nil {
+		return raw, err
+	}
+
+	if raw.GasWait, err = d.readReg(REG_GAS_WAIT0 + raw.GasIndex); err != nil {
+		return raw, err
+	}
+
+	raw.Time = time.Now()
+
+	return raw, nil
+}
+
+// compensate compensates the raw measurement with the selected compensation.
+func (d *Device) compensate(raw Raw) Measurement {
+	if d.intCompensation() {
+		return CompensateInt(d.calibration, raw)
+	}
+
+	return Compensate(d.calibration, raw)
+}
+
+// Compensate compensates a raw measurement with the floating point
+// compensation. It needs no Device, so raw measurements recorded with their
+// calibration can be compensated offline.
+func Compensate(cal Calibration, raw Raw) Measurement {
+	m := raw.measurement()
+
+	m.Temperature, m.TemperatureFine = cal.calcTemperature(raw.Temperature)
+	m.Pressure = cal.calcPressure(raw.Pressure, m.TemperatureFine)
+	m.Humidity = cal.calcHumidity(raw.Humidity, m.TemperatureFine)
+
+	// check if gas data is available
+	if m.Status&(HEAT_STAB_MSK|GASM_VALID_MSK) != 0 {
+		if cal.VariantID == VARIANT_GAS_HIGH {
+			m.GasResistance = cal.calcGasResistanceHigh(raw.Gas, raw.GasRange)
+		} else {
+			m.GasResistance = cal.calcGasResistanceLow(raw.Gas, raw.GasRange)
+		}
+	}
+
+	m.TemperatureMilli = int32(m.Temperature * 1000)
+	m.PressurePa = uint32(m.Pressure)
+	m.HumidityMilli = uint32(m.Humidity * 1000)
+	m.GasResistanceOhms = uint32(m.GasResistance)
 
-	d.TemperatureFine = var1 + var2
+	return m
+}
+
+// intCompensation reports whether the integer compensation is used.
+func (d *Device) intCompensation() bool {
+	return fixedPoint || d.config.IntCompensation
+}
 
-	return d.TemperatureFine / 5120
+// setMeasurement stores the measurement in the Device struct.
+func (d *Device) setMeasurement(m Measurement) {
+	d.last = m
 }
 
-func (d *Device) calcPressure(adcPres uint32) float32 {
-	var1 := (float32(d.TemperatureFine)/2 - 64000)
-	var2 := var1 * var1 * (float32(d.calibrationCoefficients.p6) / 131072)
-	var2 += var1 * float32(d.calibrationCoefficients.p5) * 2
-	var2 = (var2 / 4) + float32(d.calibrationCoefficients.p4)*65536
-	var1 = (((float32(d.calibrationCoefficients.p3) * var1 * var1) / 16384) + (float32(d.calibrationCoefficients.p2) * var1)) / 524288
-	var1 = (1.0 + (var1 / 32768)) * float32(d.calibrationCoefficients.p1)
+// calcTemperature returns the temperature in degree Celsius and the
+// intermediate temperature coefficient used by the other compensations.
+func (c Calibration) calcTemperature(adcTemp uint32) (float32, float32) {
+	var1 := (((float32(adcTemp) / 16384) - (float32(c.T1) / 1024)) * float32(c.T2))
+	var2 := ((((float32(adcTemp) / 131072) - (float32(c.T1) / 8192)) *
+		((float32(adcTemp) / 131072) - (float32(c.T1) / 8192))) * (float32(c.T3) * 16))
+
+	tFine := var1 + var2
+
+	return tFine / 5120, tFine
+}
+
+func (c Calibration) calcPressure(adcPres uint32, tFine float32) float32 {
+	var1 := (tFine/2 - 64000)
+	var2 := var1 * var1 * (float32(c.P6) / 131072)
+	var2 += var1 * float32(c.P5) * 2
+	var2 = (var2 / 4) + float32(c.P4)*65536
+	var1 = (((float32(c.P3) * var1 * var1) / 16384) + (float32(c.P2) * var1)) / 524288
+	var1 = (1.0 + (var1 / 32768)) * float32(c.P1)
 	calcPres := 1048576 - float32(adcPres)
 
 	// avoid division by zero
@@ -692,21 +1142,21 @@ func (d *Device) calcPressure(adcPres uint32) float32 {
 	}
 
 	calcPres = ((calcPres - (var2 / 4096)) * 6250) / var1
-	var1 = (float32(d.calibrationCoefficients.p9) * calcPres * calcPres) / 2147483648
-	var2 = calcPres * (float32(d.calibrationCoefficients.p8) / 32768)
-	var3 := ((calcPres / 256) * (calcPres / 256) * (calcPres / 256) * (float32(d.calibrationCoefficients.p10) / 131072))
-	return calcPres + (var1+var2+var3+(float32(d.calibrationCoefficients.p7)*128))/16
-}
-
-func (d *Device) calcHumidity(adcHum uint16) float32 {
-	tempComp := d.TemperatureFine / 5120.0
-	var1 := float32(adcHum) - ((float32(d.calibrationCoefficients.h1) * 16.0) +
-		((float32(d.calibrationCoefficients.h3) / 2.0) * tempComp))
-	var2 := var1 * ((float32(d.calibrationCoefficients.h2) / 262144.0) *
-		(1.0 + ((float32(d.calibrationCoefficients.h4) / 16384.0) * tempComp) +
-			((float32(d.calibrationCoefficients.h5) / 1048576.0) * tempComp * tempComp)))
-	var3 := float32(d.calibrationCoefficients.h6) / 16384.0
-	var4 := float32(d.calibrationCoefficients.h7) / 2097152.0
+	var1 = (float32(c.P9) * calcPres * calcPres) / 2147483648
+	var2 = calcPres * (float32(c.P8) / 32768)
+	var3 := ((calcPres / 256) * (calcPres / 256) * (calcPres / 256) * (float32(c.P10) / 131072))
+	return calcPres + (var1+var2+var3+(float32(c.P7)*128))/16
+}
+
+func (c Calibration) calcHumidity(adcHum uint16, tFine float32) float32 {
+	tempComp := tFine / 5120.0
+	var1 := float32(adcHum) - ((float32(c.H1) * 16.0) +
+		((float32(c.H3) / 2.0) * tempComp))
+	var2 := var1 * ((float32(c.H2) / 262144.0) *
+		(1.0 + ((float32(c.H4) / 16384.0) * tempComp) +
+			((float32(c.H5) / 1048576.0) * tempComp * tempComp)))
+	var3 := float32(c.H6) / 16384.0
+	var4 := float32(c.H7) / 2097152.0
 	calcHum := var2 + ((var3 + (var4 * tempComp)) * var2 * var2)
 
 	if calcHum > 100.0 {
@@ -720,16 +1170,16 @@ func (d *Device) calcHumidity(adcHum uint16) float32 {
 	return calcHum
 }
 
-func (d *Device) calcGasResistanceLow(adcGasRes uint16, gasRange uint8) float32 {
+func (c Calibration) calcGasResistanceLow(adcGasRes uint16, gasRange uint8) float32 {
 	gasRangeF := float32(int(1) << gasRange)
-	var1 := float32(1340.0 + (5.0 * float32(d.calibrationCoefficients.rangeSwErr)))
+	var1 := float32(1340.0 + (5.0 * float32(c.RangeSwErr)))
 	var2 := var1 * (1.0 + lookupK1Range[gasRange]/100.0)
 	var3 := 1.0 + (lookupK2Range[gasRange] / 100.0)
 
 	return 1.0 / (var3 * (0.000000125) * gasRangeF * (((float32(adcGasRes) - 512.0) / var2) + 1.0))
 }
 
-func (d *Device) calcGasResistanceHigh(adcGasRes uint16, gasRange uint8) float32 {
+func (c Calibration) calcGasResistanceHigh(adcGasRes uint16, gasRange uint8) float32 {
 	var1 := uint32(262144) >> gasRange
 	var2 := int32(adcGasRes) - 512
 
@@ -739,21 +1189,42 @@ func (d *Device) calcGasResistanceHigh(adcGasRes uint16, gasRange uint8) float32
 	return 1000000.0 * float32(var1) / float32(var2)
 }
 
-// calcMeasDuration calculates the remaining duration that can be used for heating.
-func (d *Device) calcMeasDuration() uint32 {
-	measCycles := osToMeasCycles[d.config.Temperature]
-	measCycles += osToMeasCycles[d.config.Pressure]
-	measCycles += osToMeasCycles[d.config.Humidity]
+// measDuration calculates the TPHG measurement duration in µs, heating
+// excluded.
+func (c *Config) measDuration() uint32 {
+	measCycles := osToMeasCycles[c.Temperature]
+	measCycles += osToMeasCycles[c.Pressure]
+	measCycles += osToMeasCycles[c.Humidity]
 
 	// TPHG measurement duration
 	dur := uint32(measCycles) * MeasOffset
 	dur += MeasDur
 	dur += GasDur
-	dur += WakeUpDur // wake up duration of 1ms
+
+	// no wake up in parallel mode, the sensor never goes to sleep
+	if c.mode != ModeParallel {
+		dur += WakeUpDur // wake up duration of 1ms
+	}
 
 	return dur
 }
 
+// heatrDuration returns the heating duration in ms of the next measurement.
+func (d *Device) heatrDuration() uint16 {
+	switch {
+	case d.config.mode == ModeParallel:
+		return d.config.HeatrSharedDur
+	case len(d.config.HeatrProfile) == 0:
+		return d.config.HeatrDur
+	case d.config.mode == ModeSequential:
+		return d.config.HeatrProfile[0].Dur
+	case int(d.config.HeatrStep) < len(d.config.HeatrProfile):
+		return d.config.HeatrProfile[d.config.HeatrStep].Dur
+	}
+
+	return d.config.HeatrDur
+}
+
 func (d *Device) calRemainingReadingMillis() int64 {
 	if d.measStart == 0 {
 		return -1
@@ -770,14 +1241,33 @@ func (d *Device) calRemainingReadingMillis() int64 {
 
 // calcGasWait calculates the gas wait period. It takes the heater duration
 // in ms and returns the calculated gas wait period.
-func (d *Device) calcGasWait() uint8 {
+func (d *Device) calcGasWait(dur uint16) uint8 {
+	var factor uint8
+
+	if dur >= MaxHeatrDuration {
+		return MaxDuration
+	}
+
+	for dur > 0x3F {
+		dur /= 4
+		factor++
+	}
+
+	return uint8(uint8(dur) + (factor * 64))
+}
+
+// calcHeatrDurShared calculates the shared heater duration register value
+// used in parallel mode. It takes the duration in ms.
+func calcHeatrDurShared(dur uint16) uint8 {
 	var factor uint8
-	dur := d.config.HeatrDur
 
-	if dur >= 0xFC0 {
+	if dur >= MaxSharedDuration {
 		return MaxDuration
 	}
 
+	// step size of 0.477ms
+	dur = uint16((uint32(dur) * 1000) / 477)
+
 	for dur > 0x3F {
 		dur /= 4
 		factor++
@@ -786,24 +1276,34 @@ func (d *Device) calcGasWait() uint8 {
 	return uint8(uint8(dur) + (factor * 64))
 }
 
+// resistanceHeat calculates the heater resistance value with the selected
+// compensation.
+func (d *Device) resistanceHeat(target uint16) uint8 {
+	if d.intCompensation() {
+		return d.calcResistanceHeatInt(target)
+	}
+
+	return d.calcResistanceHeat(target)
+}
+
 // calcResistanceHeat calculates the heater resistance value. It takes the target
 // temperature in degree Celsius and returns the calculated heater resistance
 // value.
 func (d *Device) calcResistanceHeat(target uint16) uint8 {
 	// cap temperature to 400°C
-	if target > 400 {
-		target = 400
+	if target > MaxHeatrTemperature {
+		target = MaxHeatrTemperature
 	}
 
-	var1 := (float32(d.calibrationCoefficients.g1) / 16.0) + 49
-	var2 := ((float32(d.calibrationCoefficients.g2) / 32768.0) * 0.0005) + 0.00235
-	var3 := float32(d.calibrationCoefficients.g3) / 1024
+	var1 := (float32(d.calibration.G1) / 16.0) + 49
+	var2 := ((float32(d.calibration.G2) / 32768.0) * 0.0005) + 0.00235
+	var3 := float32(d.calibration.G3) / 1024
 	var4 := var1 * (1 + (var2 * float32(target)))
 	var5 := var4 + (var3 * float32(d.config.AmbientTemperature))
 
 	return uint8(3.4 *
-		((var5 * (4 / (4 + float32(d.calibrationCoefficients.resHeatRange))) *
-			(1 / (1 + (float32(d.calibrationCoefficients.resHeatVal) * 0.002)))) -
+		((var5 * (4 / (4 + float32(d.calibration.ResHeatRange))) *
+			(1 / (1 + (float32(d.calibration.ResHeatVal) * 0.002)))) -
 			25))
 }
 
@@ -828,6 +1328,59 @@ func (d *Device) Config() Config {
 	return *d.config
 }
 
+// NewData reports whether the measurement holds new data.
+func (m Measurement) NewData() bool {
+	return m.Status&NEW_DATA_MSK != 0
+}
+
+// GasValid reports whether the gas measurement is valid.
+func (m Measurement) GasValid() bool {
+	return m.Status&GASM_VALID_MSK != 0
+}
+
+// HeatStable reports whether the heater reached the target temperature.
+func (m Measurement) HeatStable() bool {
+	return m.Status&HEAT_STAB_MSK != 0
+}
+
+// GasErr returns ErrGasInvalid or ErrHeaterUnstable when the gas resistance
+// can not be trusted, nil otherwise. It is only meaningful when the gas
+// measurement is enabled.
+func (m Measurement) GasErr() error {
+	if !m.GasValid() {
+		return ErrGasInvalid
+	}
+
+	if !m.HeatStable() {
+		return ErrHeaterUnstable
+	}
+
+	return nil
+}
+
+// sleep pauses for the duration, or until the context is done.
+func sleep(ctx context.Context, dur time.Duration) error {
+	// no timer needed when the context is never done
+	if ctx.Done() == nil {
+		time.Sleep(dur)
+		return nil
+	}
+
+	if dur <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(dur)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // parseByte converts two bytes to T16.
 func parseByte[T uint16 | int16](msb, lsb byte) T {
 	return (T(msb) << 8) | T(lsb)
@@ -836,29 +1389,40 @@ func parseByte[T uint16 | int16](msb, lsb byte) T {
 // String implements fmt.Stringer interface.
 func (c Config) String() string {
 	return fmt.Sprintf("pressure: %d, temperature: %d, humidity: %d, iir: %d, odr: %d, heatrTemp: %d°C, heatrDur: %dms,"+
-		" heatrEnable: %t, ambientTemperature: %d, mode: %d",
-		c.Pressure, c.Temperature, c.Humidity, c.IIR, c.ODR, c.HeatrTemp, c.HeatrDur, c.HeatrEnable, c.AmbientTemperature,
-		c.mode,
+		" heatrEnable: %t, heatrProfile: %v, heatrSharedDur: %dms, heatrStep: %d, heatrCycle: %t,"+
+		" intCompensation: %t, ambientTemperature: %d, mode: %d",
+		c.Pressure, c.Temperature, c.Humidity, c.IIR, c.ODR, c.HeatrTemp, c.HeatrDur, c.HeatrEnable, c.HeatrProfile,
+		c.HeatrSharedDur, c.HeatrStep, c.HeatrCycle, c.IntCompensation, c.AmbientTemperature, c.mode,
 	)
 }
 
 // String implements fmt.Stringer interface.
-func (c calibrationCoefficients) String() string {
-	return fmt.Sprintf(`temperature: t1: %d, t2: %d, t3: %d, pressure: p1: %d, p2: %d, p3: %d, p4: %d,
-	p5: %d, p6: %d, p7: %d, p8: %d, p9: %d, p10: %d, humidity: h1: %d, h2: %d, h3: %d, h4: %d, h5: %d,
-  	h6: %d, h7: %d, gas: g1: %d, g2: %d, g3: %d, res_heat_range: %d, res_heat_val: %d, range_sw_err: %d`,
-		c.t1, c.t2, c.t3, c.p1, c.p2, c.p3, c.p4,
-		c.p5, c.p6, c.p7, c.p8, c.p9, c.p10, c.h1, c.h2, c.h3, c.h4, c.h5,
-		c.h6, c.h7, c.g1, c.g2, c.g3, c.resHeatRange, c.resHeatVal, c.rangeSwErr,
+func (m Measurement) String() string {
+	if fixedPoint {
+		return fmt.Sprintf("status: 0x%X, gas index: %d, meas index: %d, temperature: %dm°C, pressure: %dPa,"+
+			" humidity: %dm%%, res gas: %dΩ, res heat: %dΩ, gas wait: %dms, idac: %d",
+			m.Status, m.GasIndex, m.MeasIndex, m.TemperatureMilli, m.PressurePa,
+			m.HumidityMilli, m.GasResistanceOhms, m.ResHeat, m.GasWait, m.Idac,
+		)
+	}
+
+	return fmt.Sprintf("status: 0x%X, gas index: %d, meas index: %d, temperature: %.2f°C, pressure: %.2fPa,"+
+		" humidity: %.2f%%, res gas: %.2fΩ, res heat: %dΩ, gas wait: %dms, idac: %d",
+		m.Status, m.GasIndex, m.MeasIndex, m.Temperature, m.Pressure,
+		m.Humidity, m.GasResistance, m.ResHeat, m.GasWait, m.Idac,
 	)
 }
 
 // String implements fmt.Stringer interface.
 func (d Device) String() string {
+	if fixedPoint {
+		return fmt.Sprintf("address: 0x%X, chip id: 0x%X, variant id: 0x%X, %s", d.address, d.chipID, d.VariantID, d.last)
+	}
+
 	return fmt.Sprintf("address: 0x%X, chip id: 0x%X, variant id: 0x%X, status: 0x%X,"+
 		" temperature fine:%.2f, temperature: %.2f°C, pressure: %.2fPa, humidity: %.2f%%,"+
-		" res gas: %.2fΩ, res heat: %dΩ, gas wait: %dms, idac: %d",
-		d.address, d.chipID, d.VariantID, d.Status, d.TemperatureFine, d.Temperature,
-		d.Pressure, d.Humidity, d.GasResistance, d.ResHeat, d.GasWait, d.Idac,
+		" res gas: %.2fΩ, gas index: %d, res heat: %dΩ, gas wait: %dms, idac: %d",
+		d.address, d.chipID, d.VariantID, d.last.Status, d.last.TemperatureFine, d.last.Temperature,
+		d.last.Pressure, d.last.Humidity, d.last.GasResistance, d.last.GasIndex, d.last.ResHeat, d.last.GasWait, d.last.Idac,
 	)
 }
@@ -0,0 +1,251 @@
+package bme68x
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StudioConfig is a board configuration exported by Bosch AI Studio in a
+// .bmeconfig file. It holds the heater profiles and duty cycles designed for
+// the BME688 parallel mode, and assigns them to the sensors of the board.
+type StudioConfig struct {
+	HeaterProfiles []StudioHeaterProfile
+	DutyCycles     []StudioDutyCycle
+	Sensors        []StudioSensor
+}
+
+// StudioHeaterProfile is a heater profile of a StudioConfig.
+type StudioHeaterProfile struct {
+	ID string
+	// TimeBase is the duration in ms of a step multiplier, TPHG measurement
+	// included.
+	TimeBase uint16
+	// Steps are the temperatures in degree Celsius, with durations in
+	// multipliers of TimeBase.
+	Steps []HeaterStep
+}
+
+// StudioDutyCycle is a duty cycle of a StudioConfig: the heater profile runs
+// ScanningCycles times, then the sensor sleeps for SleepingCycles profile
+// durations.
+type StudioDutyCycle struct {
+	ID             string
+	ScanningCycles uint16
+	SleepingCycles uint16
+}
+
+// StudioSensor assigns a heater profile and a duty cycle to a sensor of the
+// board.
+type StudioSensor struct {
+	Index         int
+	Active        bool
+	HeaterProfile string
+	DutyCycle     string
+}
+
+// studioFile is the JSON layout of a .bmeconfig file.
+type studioFile struct {
+	ConfigBody studioBody `json:"configBody"`
+}
+
+// studioBody is the configuration body of .bmeconfig and .bmerawdata files.
+type studioBody struct {
+	HeaterProfiles       []studioHeaterProfile `json:"heaterProfiles"`
+	DutyCycleProfiles    []studioDutyCycle     `json:"dutyCycleProfiles"`
+	SensorConfigurations []studioSensor        `json:"sensorConfigurations"`
+}
+
+type studioHeaterProfile struct {
+	ID                     string     `json:"id"`
+	TimeBase               uint16     `json:"timeBase"`
+	TemperatureTimeVectors [][]uint16 `json:"temperatureTimeVectors"`
+}
+
+type studioDutyCycle struct {
+	ID                   string `json:"id"`
+	NumberScanningCycles uint16 `json:"numberScanningCycles"`
+	NumberSleepingCycles uint16 `json:"numberSleepingCycles"`
+}
+
+type studioSensor struct {
+	SensorIndex      int    `json:"sensorIndex"`
+	Active           bool   `json:"active"`
+	HeaterProfile    string `json:"heaterProfile"`
+	DutyCycleProfile string `json:"dutyCycleProfile"`
+}
+
+// ParseStudioConfig reads a .bmeconfig file exported by Bosch AI Studio. The
+// heater profiles are checked against the driver limits, and the profiles
+// referenced by the sensors must exist.
+func ParseStudioConfig(r io.Reader) (StudioConfig, error) {
+	var file studioFile
+
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return StudioConfig{}, fmt.Errorf("failed to decode bmeconfig: %w", err)
+	}
+
+	return file.ConfigBody.config()
+}
+
+// config converts and checks the configuration body.
+func (body *studioBody) config() (StudioConfig, error) {
+	var c StudioConfig
+
+	for _, p := range body.HeaterProfiles {
+		profile := StudioHeaterProfile{
+			ID:       p.ID,
+			TimeBase: p.TimeBase,
+		}
+
+		for i, v := range p.TemperatureTimeVectors {
+			if len(v) != 2 {
+				return c, fmt.Errorf("%w: heater profile %q step %d: expected temperature and duration, got %v", ErrInvalidConfig, p.ID, i, v)
+			}
+
+			profile.Steps = append(profile.Steps, HeaterStep{Temp: v[0], Dur: v[1]})
+		}
+
+		if _, err := profile.Config(Config{}); err != nil {
+			return c, fmt.Errorf("heater profile %q: %w", p.ID, err)
+		}
+
+		c.HeaterProfiles = append(c.HeaterProfiles, profile)
+	}
+
+	for _, d := range body.DutyCycleProfiles {
+		c.DutyCycles = append(c.DutyCycles, StudioDutyCycle{
+			ID:             d.ID,
+			ScanningCycles: d.NumberScanningCycles,
+			SleepingCycles: d.NumberSleepingCycles,
+		})
+	}
+
+	for _, s := range body.SensorConfigurations {
+		sensor := StudioSensor{
+			Index:         s.SensorIndex,
+			Active:        s.Active,
+			HeaterProfile: s.HeaterProfile,
+			DutyCycle:     s.DutyCycleProfile,
+		}
+
+		if _, ok := c.HeaterProfile(sensor.HeaterProfile); !ok {
+			return c, fmt.Errorf("%w: sensor %d: unknown heater profile %q", ErrInvalidConfig, sensor.Index, sensor.HeaterProfile)
+		}
+
+		if _, ok := c.DutyCycle(sensor.DutyCycle); sensor.DutyCycle != "" && !ok {
+			return c, fmt.Errorf("%w: sensor %d: unknown duty cycle %q", ErrInvalidConfig, sensor.Index, sensor.DutyCycle)
+		}
+
+		c.Sensors = append(c.Sensors, sensor)
+	}
+
+	return c, nil
+}
+
+// body returns the configuration body of the configuration.
+func (c StudioConfig) body() studioBody {
+	body := studioBody{
+		HeaterProfiles:       []studioHeaterProfile{},
+		DutyCycleProfiles:    []studioDutyCycle{},
+		SensorConfigurations: []studioSensor{},
+	}
+
+	for _, p := range c.HeaterProfiles {
+		profile := studioHeaterProfile{
+			ID:                     p.ID,
+			TimeBase:               p.TimeBase,
+			TemperatureTimeVectors: [][]uint16{},
+		}
+
+		for _, step := range p.Steps {
+			profile.TemperatureTimeVectors = append(profile.TemperatureTimeVectors, []uint16{step.Temp, step.Dur})
+		}
+
+		body.HeaterProfiles = append(body.HeaterProfiles, profile)
+	}
+
+	for _, d := range c.DutyCycles {
+		body.DutyCycleProfiles = append(body.DutyCycleProfiles, studioDutyCycle{
+			ID:                   d.ID,
+			NumberScanningCycles: d.ScanningCycles,
+			NumberSleepingCycles: d.SleepingCycles,
+		})
+	}
+
+	for _, s := range c.Sensors {
+		body.SensorConfigurations = append(body.SensorConfigurations, studioSensor{
+			SensorIndex:      s.Index,
+			Active:           s.Active,
+			HeaterProfile:    s.HeaterProfile,
+			DutyCycleProfile: s.DutyCycle,
+		})
+	}
+
+	return body
+}
+
+// HeaterProfile returns the heater profile with the ID.
+func (c StudioConfig) HeaterProfile(id string) (StudioHeaterProfile, bool) {
+	for _, p := range c.HeaterProfiles {
+		if p.ID == id {
+			return p, true
+		}
+	}
+
+	return StudioHeaterProfile{}, false
+}
+
+// DutyCycle returns the duty cycle with the ID.
+func (c StudioConfig) DutyCycle(id string) (StudioDutyCycle, bool) {
+	for _, d := range c.DutyCycles {
+		if d.ID == id {
+			return d, true
+		}
+	}
+
+	return StudioDutyCycle{}, false
+}
+
+// Sensor returns the configuration of the sensor at the board index, with
+// its heater profile applied to base as in StudioHeaterProfile.Config.
+func (c StudioConfig) Sensor(index int, base Config) (Config, error) {
+	for _, s := range c.Sensors {
+		if s.Index != index {
+			continue
+		}
+
+		profile, _ := c.HeaterProfile(s.HeaterProfile)
+
+		return profile.Config(base)
+	}
+
+	return base, fmt.Errorf("%w: no configuration for sensor %d", ErrInvalidConfig, index)
+}
+
+// Config returns base set up for the heater profile in parallel mode: the
+// shared heater duration is the time base minus the TPHG measurement duration
+// for the oversampling of base. The result is validated; apply it with
+// SetConfig then SetMode(ModeParallel).
+func (p StudioHeaterProfile) Config(base Config) (Config, error) {
+	base.mode = ModeParallel
+	base.HeatrEnable = true
+	base.HeatrProfile = append([]HeaterStep(nil), p.Steps...)
+
+	if err := base.validateOversampling(); err != nil {
+		return base, err
+	}
+
+	measDur := base.measDuration() / 1000
+	if uint32(p.TimeBase) <= measDur {
+		return base, fmt.Errorf("%w: time base %dms shorter than the measurement: %dms", ErrInvalidConfig, p.TimeBase, measDur)
+	}
+
+	base.HeatrSharedDur = p.TimeBase - uint16(measDur)
+
+	if err := base.Validate(); err != nil {
+		return base, err
+	}
+
+	return base, nil
+}
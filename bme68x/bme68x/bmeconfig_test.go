@@ -0,0 +1,144 @@
+package bme68x
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// heater354 is the heater profile HP-354 of AI Studio.
+var heater354 = []HeaterStep{
+	{320, 5}, {100, 2}, {100, 10}, {100, 30}, {200, 5},
+	{200, 5}, {200, 5}, {320, 5}, {320, 5}, {320, 5},
+}
+
+func loadStudioConfig(t *testing.T) StudioConfig {
+	t.Helper()
+
+	f, err := os.Open("testdata/board.bmeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	c, err := ParseStudioConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestParseStudioConfig(t *testing.T) {
+	c := loadStudioConfig(t)
+
+	if len(c.HeaterProfiles) != 2 || len(c.DutyCycles) != 2 || len(c.Sensors) != 3 {
+		t.Fatalf("%d heater profiles, %d duty cycles and %d sensors, want 2, 2 and 3",
+			len(c.HeaterProfiles), len(c.DutyCycles), len(c.Sensors))
+	}
+
+	p, ok := c.HeaterProfile("heater_354")
+	if !ok || p.TimeBase != 140 || !reflect.DeepEqual(p.Steps, heater354) {
+		t.Errorf("heater profile %+v, want time base 140 and steps %v", p, heater354)
+	}
+
+	if d, ok := c.DutyCycle("duty_5"); !ok || d.ScanningCycles != 1 || d.SleepingCycles != 4 {
+		t.Errorf("duty cycle %+v, want 1 scanning and 4 sleeping cycles", d)
+	}
+
+	want := StudioSensor{Index: 2, Active: false, HeaterProfile: "heater_354", DutyCycle: "duty_1"}
+	if c.Sensors[2] != want {
+		t.Errorf("sensor %+v, want %+v", c.Sensors[2], want)
+	}
+}
+
+func TestStudioConfigSensor(t *testing.T) {
+	c := loadStudioConfig(t)
+	base := Config{Temperature: Sampling2X, Pressure: Sampling16X, Humidity: Sampling1X}
+
+	got, err := c.Sensor(0, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 19 cycles of 1963µs, the TPH switching and the gas measurement
+	const measDur = (19*1963 + 1908 + 2385) / 1000
+
+	if got.HeatrSharedDur != 140-measDur {
+		t.Errorf("shared heater duration %dms, want %dms", got.HeatrSharedDur, 140-measDur)
+	}
+
+	if !reflect.DeepEqual(got.HeatrProfile, heater354) || !got.HeatrEnable || got.mode != ModeParallel {
+		t.Errorf("config %v, want the heater profile %v in parallel mode", got, heater354)
+	}
+
+	// the profile is copied
+	got.HeatrProfile[0].Temp = 0
+	if p, _ := c.HeaterProfile("heater_354"); p.Steps[0].Temp != 320 {
+		t.Error("the config shares the steps of the heater profile")
+	}
+
+	if _, err := c.Sensor(7, base); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Sensor of a missing sensor returned %v, want %v", err, ErrInvalidConfig)
+	}
+}
+
+func TestStudioHeaterProfileConfigInvalid(t *testing.T) {
+	p := StudioHeaterProfile{ID: "heater_354", TimeBase: 140, Steps: heater354}
+
+	for _, c := range []struct {
+		name string
+		p    StudioHeaterProfile
+		base Config
+	}{
+		// about 98ms of measurement
+		{"short time base", StudioHeaterProfile{TimeBase: 90, Steps: heater354},
+			Config{Temperature: Sampling16X, Pressure: Sampling16X, Humidity: Sampling16X}},
+		{"oversampling", p, Config{Temperature: Sampling16X + 1}},
+		{"pressure oversampling", p, Config{Pressure: 0xFF}},
+		{"no steps", StudioHeaterProfile{TimeBase: 140}, Config{}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := c.p.Config(c.base); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Config returned %v, want %v", err, ErrInvalidConfig)
+			}
+		})
+	}
+}
+
+func TestParseStudioConfigInvalid(t *testing.T) {
+	data, err := os.ReadFile("testdata/board.bmeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := string(data)
+
+	for _, c := range []struct {
+		name     string
+		old, new string
+	}{
+		{"unknown heater profile", `"heaterProfile": "heater_301"`, `"heaterProfile": "heater_999"`},
+		{"unknown duty cycle", `"dutyCycleProfile": "duty_5"`, `"dutyCycleProfile": "duty_9"`},
+		{"step without duration", "[100, 43]", "[100]"},
+		{"step with 3 values", "[100, 43]", "[100, 43, 1]"},
+		{"short time base", `"timeBase": 140`, `"timeBase": 4`},
+		{"hot step", "[100, 43]", "[500, 43]"},
+		{"truncated", valid, valid[:len(valid)/2]},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			data := strings.Replace(valid, c.old, c.new, 1)
+			if data == valid {
+				t.Fatalf("%q not found in the fixture", c.old)
+			}
+
+			if _, err := ParseStudioConfig(strings.NewReader(data)); err == nil {
+				t.Error("ParseStudioConfig succeeded")
+			} else if c.name != "truncated" && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("ParseStudioConfig returned %v, want %v", err, ErrInvalidConfig)
+			}
+		})
+	}
+}
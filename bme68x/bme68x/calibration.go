@@ -0,0 +1,279 @@
+package bme68x
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+)
+
+const (
+	// CalibrationSize is the size of the binary encoding of a Calibration:
+	// the format version, the variant ID, the coefficients in little endian
+	// and a CRC-32 (IEEE) of the previous bytes.
+	CalibrationSize = 43
+	// CalibrationVersion is the version of the binary encoding.
+	CalibrationVersion uint8 = 1
+)
+
+// Calibration holds the calibration coefficients programmed in the sensor at
+// the factory, and the variant ID selecting the gas resistance formula. It
+// can be stored with recorded raw data to compensate it later.
+type Calibration struct {
+	VariantID uint8 `json:"variant_id"`
+
+	// temperature related coefficients
+	T1 uint16 `json:"t1"`
+	T2 int16  `json:"t2"`
+	T3 int8   `json:"t3"`
+
+	// pressure related coefficients
+	P1  uint16 `json:"p1"`
+	P2  int16  `json:"p2"`
+	P3  int8   `json:"p3"`
+	P4  int16  `json:"p4"`
+	P5  int16  `json:"p5"`
+	P6  int8   `json:"p6"`
+	P7  int8   `json:"p7"`
+	P8  int16  `json:"p8"`
+	P9  int16  `json:"p9"`
+	P10 uint8  `json:"p10"`
+
+	// humidity related coefficients
+	H1 uint16 `json:"h1"`
+	H2 uint16 `json:"h2"`
+	H3 int8   `json:"h3"`
+	H4 int8   `json:"h4"`
+	H5 int8   `json:"h5"`
+	H6 uint8  `json:"h6"`
+	H7 int8   `json:"h7"`
+
+	// gas related coefficients
+	G1 int8  `json:"g1"`
+	G2 int16 `json:"g2"`
+	G3 int8  `json:"g3"`
+
+	// other coefficients
+	ResHeatRange uint8 `json:"res_heat_range"`
+	ResHeatVal   int8  `json:"res_heat_val"`
+	RangeSwErr   int8  `json:"range_sw_err"`
+}
+
+// calibrationJSON is the JSON encoding of a Calibration, with the CRC of its
+// binary encoding.
+type calibrationJSON struct {
+	calibrationFields
+	CRC uint32 `json:"crc"`
+}
+
+// calibrationFields has the fields of Calibration without its methods.
+type calibrationFields Calibration
+
+// parseCalibration decodes the REG_COEFF1, REG_COEFF2 and REG_COEFF3 blocks.
+func parseCalibration(data [42]byte) Calibration {
+	return Calibration{
+		// temperature related coefficients
+		T1: parseByte[uint16](data[32], data[31]),
+		T2: parseByte[int16](data[1], data[0]),
+		T3: int8(data[2]),
+
+		// pressure related coefficients
+		P1:  parseByte[uint16](data[5], data[4]),
+		P2:  parseByte[int16](data[7], data[6]),
+		P3:  int8(data[8]),
+		P4:  parseByte[int16](data[11], data[10]),
+		P5:  parseByte[int16](data[13], data[12]),
+		P6:  int8(data[15]),
+		P7:  int8(data[14]),
+		P8:  parseByte[int16](data[19], data[18]),
+		P9:  parseByte[int16](data[21], data[20]),
+		P10: data[22],
+
+		// humidity related coefficients
+		H1: uint16(data[25])<<4 | uint16(data[24])&0x0F,
+		H2: uint16(data[23])<<4 | uint16(data[24])>>4,
+		H3: int8(data[26]),
+		H4: int8(data[27]),
+		H5: int8(data[28]),
+		H6: data[29],
+		H7: int8(data[30]),
+
+		// gas heater related coefficients
+		G1: int8(data[35]),
+		G2: parseByte[int16](data[34], data[33]),
+		G3: int8(data[36]),
+
+		// other coefficients
+		ResHeatRange: (data[39] & 0x30) / 16,
+		ResHeatVal:   int8(data[37]),
+		RangeSwErr:   int8(data[41]&0xF0) / 16,
+	}
+}
+
+// Calibration returns the calibration read from the sensor by Configure, or
+// the one loaded with LoadCalibration.
+func (d *Device) Calibration() Calibration {
+	c := d.calibration
+	c.VariantID = d.VariantID
+
+	return c
+}
+
+// LoadCalibration replaces the calibration read from the sensor, which
+// Configure reads again. The heater configuration depends on the calibration
+// and is rewritten.
+func (d *Device) LoadCalibration(c Calibration) error {
+	if c.VariantID != d.VariantID {
+		return fmt.Errorf("%w: variant ID 0x%02X, expected 0x%02X", ErrInvalidCalibration, c.VariantID, d.VariantID)
+	}
+
+	d.calibration = c
+
+	if err := d.applyGasConfig(context.Background()); err != nil {
+		return fmt.Errorf("failed to apply gas config: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler interface.
+func (c Calibration) MarshalBinary() ([]byte, error) {
+	return c.appendBinary(make([]byte, 0, CalibrationSize)), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler interface. It checks
+// the version and the CRC.
+func (c *Calibration) UnmarshalBinary(data []byte) error {
+	if len(data) != CalibrationSize {
+		return fmt.Errorf("%w: size %d, expected %d", ErrInvalidCalibration, len(data), CalibrationSize)
+	}
+
+	if data[0] != CalibrationVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidCalibration, data[0])
+	}
+
+	crc := binary.LittleEndian.Uint32(data[CalibrationSize-4:])
+	if sum := crc32.ChecksumIEEE(data[:CalibrationSize-4]); sum != crc {
+		return fmt.Errorf("%w: CRC 0x%08X, expected 0x%08X", ErrInvalidCalibration, crc, sum)
+	}
+
+	le := binary.LittleEndian
+	*c = Calibration{
+		VariantID: data[1],
+
+		T1: le.Uint16(data[2:]),
+		T2: int16(le.Uint16(data[4:])),
+		T3: int8(data[6]),
+
+		P1:  le.Uint16(data[7:]),
+		P2:  int16(le.Uint16(data[9:])),
+		P3:  int8(data[11]),
+		P4:  int16(le.Uint16(data[12:])),
+		P5:  int16(le.Uint16(data[14:])),
+		P6:  int8(data[16]),
+		P7:  int8(data[17]),
+		P8:  int16(le.Uint16(data[18:])),
+		P9:  int16(le.Uint16(data[20:])),
+		P10: data[22],
+
+		H1: le.Uint16(data[23:]),
+		H2: le.Uint16(data[25:]),
+		H3: int8(data[27]),
+		H4: int8(data[28]),
+		H5: int8(data[29]),
+		H6: data[30],
+		H7: int8(data[31]),
+
+		G1: int8(data[32]),
+		G2: int16(le.Uint16(data[33:])),
+		G3: int8(data[35]),
+
+		ResHeatRange: data[36],
+		ResHeatVal:   int8(data[37]),
+		RangeSwErr:   int8(data[38]),
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface. The CRC of the binary
+// encoding is added as the crc field.
+func (c Calibration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(calibrationJSON{
+		calibrationFields: calibrationFields(c),
+		CRC:               c.CRC(),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. It checks the crc
+// field.
+func (c *Calibration) UnmarshalJSON(data []byte) error {
+	var v calibrationJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	cal := Calibration(v.calibrationFields)
+	if sum := cal.CRC(); sum != v.CRC {
+		return fmt.Errorf("%w: CRC 0x%08X, expected 0x%08X", ErrInvalidCalibration, v.CRC, sum)
+	}
+
+	*c = cal
+
+	return nil
+}
+
+// CRC returns the CRC-32 (IEEE) of the binary encoding, without the CRC.
+func (c Calibration) CRC() uint32 {
+	var buf [CalibrationSize]byte
+
+	data := c.appendBinary(buf[:0])
+
+	return binary.LittleEndian.Uint32(data[CalibrationSize-4:])
+}
+
+// appendBinary appends the binary encoding, CRC included, to data.
+func (c Calibration) appendBinary(data []byte) []byte {
+	start := len(data)
+	le := binary.LittleEndian
+
+	data = append(data, CalibrationVersion, c.VariantID)
+
+	data = le.AppendUint16(data, c.T1)
+	data = le.AppendUint16(data, uint16(c.T2))
+	data = append(data, byte(c.T3))
+
+	data = le.AppendUint16(data, c.P1)
+	data = le.AppendUint16(data, uint16(c.P2))
+	data = append(data, byte(c.P3))
+	data = le.AppendUint16(data, uint16(c.P4))
+	data = le.AppendUint16(data, uint16(c.P5))
+	data = append(data, byte(c.P6), byte(c.P7))
+	data = le.AppendUint16(data, uint16(c.P8))
+	data = le.AppendUint16(data, uint16(c.P9))
+	data = append(data, c.P10)
+
+	data = le.AppendUint16(data, c.H1)
+	data = le.AppendUint16(data, c.H2)
+	data = append(data, byte(c.H3), byte(c.H4), byte(c.H5), c.H6, byte(c.H7))
+
+	data = append(data, byte(c.G1))
+	data = le.AppendUint16(data, uint16(c.G2))
+	data = append(data, byte(c.G3))
+
+	data = append(data, c.ResHeatRange, byte(c.ResHeatVal), byte(c.RangeSwErr))
+
+	return le.AppendUint32(data, crc32.ChecksumIEEE(data[start:]))
+}
+
+// String implements fmt.Stringer interface.
+func (c Calibration) String() string {
+	return fmt.Sprintf(`variant: 0x%02X, temperature: t1: %d, t2: %d, t3: %d, pressure: p1: %d, p2: %d, p3: %d, p4: %d,
+	p5: %d, p6: %d, p7: %d, p8: %d, p9: %d, p10: %d, humidity: h1: %d, h2: %d, h3: %d, h4: %d, h5: %d,
+  	h6: %d, h7: %d, gas: g1: %d, g2: %d, g3: %d, res_heat_range: %d, res_heat_val: %d, range_sw_err: %d`,
+		c.VariantID, c.T1, c.T2, c.T3, c.P1, c.P2, c.P3, c.P4,
+		c.P5, c.P6, c.P7, c.P8, c.P9, c.P10, c.H1, c.H2, c.H3, c.H4, c.H5,
+		c.H6, c.H7, c.G1, c.G2, c.G3, c.ResHeatRange, c.ResHeatVal, c.RangeSwErr,
+	)
+}
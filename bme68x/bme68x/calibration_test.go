@@ -0,0 +1,176 @@
+package bme68x
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"testing"
+)
+
+// goldenCalibrations returns the calibrations of the golden vectors, as
+// BME688 calibrations.
+func goldenCalibrations(t *testing.T) []Calibration {
+	t.Helper()
+
+	var (
+		calibrations []Calibration
+		seen         = map[string]bool{}
+	)
+
+	for _, v := range loadGolden(t).Compensation {
+		if seen[v.Calibration] {
+			continue
+		}
+
+		seen[v.Calibration] = true
+
+		c := goldenCalibration(t, v.Calibration)
+		c.VariantID = VARIANT_GAS_HIGH
+		calibrations = append(calibrations, c)
+	}
+
+	return calibrations
+}
+
+func TestCalibrationRoundTrip(t *testing.T) {
+	var unsignedP10 bool
+
+	for i, c := range goldenCalibrations(t) {
+		// par_p10 is unsigned
+		unsignedP10 = unsignedP10 || c.P10 >= 0x80
+
+		data, err := c.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		} else if len(data) != CalibrationSize {
+			t.Fatalf("image %d: %d bytes, want %d", i, len(data), CalibrationSize)
+		}
+
+		var got Calibration
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Errorf("image %d: UnmarshalBinary: %v", i, err)
+		} else if got != c {
+			t.Errorf("image %d: binary round trip %v, want %v", i, got, c)
+		}
+
+		data, err = json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got = Calibration{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Errorf("image %d: UnmarshalJSON: %v\n%s", i, err, data)
+		} else if got != c {
+			t.Errorf("image %d: JSON round trip %v, want %v", i, got, c)
+		}
+	}
+
+	if !unsignedP10 {
+		t.Error("no golden calibration with par_p10 >= 0x80")
+	}
+}
+
+func TestCalibrationUnmarshalBinaryInvalid(t *testing.T) {
+	c := goldenCalibrations(t)[0]
+
+	valid, err := c.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// withCRC returns data with its CRC recomputed
+	withCRC := func(data []byte) []byte {
+		return binary.LittleEndian.AppendUint32(data[:len(data)-4:len(data)-4], crc32.ChecksumIEEE(data[:len(data)-4]))
+	}
+
+	for _, tc := range []struct {
+		name   string
+		modify func(data []byte) []byte
+	}{
+		{"flipped coefficient", func(data []byte) []byte { data[10] ^= 0x01; return data }},
+		{"flipped CRC", func(data []byte) []byte { data[len(data)-1] ^= 0x80; return data }},
+		{"short", func(data []byte) []byte { return data[:len(data)-1] }},
+		{"long", func(data []byte) []byte { return append(data, 0) }},
+		{"empty", func([]byte) []byte { return nil }},
+		{"version", func(data []byte) []byte { data[0] = CalibrationVersion + 1; return withCRC(data) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := c
+			got.T1++
+
+			data := tc.modify(append([]byte(nil), valid...))
+			if err := got.UnmarshalBinary(data); !errors.Is(err, ErrInvalidCalibration) {
+				t.Errorf("UnmarshalBinary returned %v, want %v", err, ErrInvalidCalibration)
+			}
+
+			if got.T1 != c.T1+1 {
+				t.Error("UnmarshalBinary changed the calibration on error")
+			}
+		})
+	}
+}
+
+func TestCalibrationUnmarshalJSONInvalid(t *testing.T) {
+	c := goldenCalibrations(t)[0]
+
+	for _, tc := range []struct {
+		name   string
+		modify func(v map[string]any)
+	}{
+		{"tampered crc", func(v map[string]any) { v["crc"] = v["crc"].(float64) + 1 }},
+		{"tampered coefficient", func(v map[string]any) { v["t1"] = v["t1"].(float64) + 1 }},
+		{"no crc", func(v map[string]any) { delete(v, "crc") }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var v map[string]any
+			if err := json.Unmarshal(data, &v); err != nil {
+				t.Fatal(err)
+			}
+
+			tc.modify(v)
+
+			if data, err = json.Marshal(v); err != nil {
+				t.Fatal(err)
+			}
+
+			var got Calibration
+			if err := json.Unmarshal(data, &got); !errors.Is(err, ErrInvalidCalibration) {
+				t.Errorf("Unmarshal of %s returned %v, want %v", data, err, ErrInvalidCalibration)
+			}
+		})
+	}
+}
+
+func TestLoadCalibration(t *testing.T) {
+	c := goldenCalibrations(t)[0]
+
+	d := NewI2C(&memI2C{})
+	d.VariantID = VARIANT_GAS_HIGH
+
+	saved := d.Calibration()
+
+	other := c
+	other.VariantID = 0
+	if err := d.LoadCalibration(other); !errors.Is(err, ErrInvalidCalibration) {
+		t.Errorf("LoadCalibration of a BME680 calibration returned %v, want %v", err, ErrInvalidCalibration)
+	}
+
+	if d.Calibration() != saved {
+		t.Error("LoadCalibration changed the calibration on error")
+	}
+
+	if err := d.LoadCalibration(c); err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Calibration() != c {
+		t.Errorf("calibration %v after LoadCalibration, want %v", d.Calibration(), c)
+	}
+}
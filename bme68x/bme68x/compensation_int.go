@@ -0,0 +1,166 @@
+package bme68x
+
+var (
+	lookupGasRange1 = [16]uint32{
+		2147483647, 2147483647, 2147483647, 2147483647, 2147483647, 2126008810, 2147483647, 2130303777,
+		2147483647, 2147483647, 2143188679, 2136746228, 2147483647, 2126008810, 2147483647, 2147483647,
+	}
+	lookupGasRange2 = [16]uint32{
+		4096000000, 2048000000, 1024000000, 512000000, 255744255, 127110228, 64000000, 32258064,
+		16016016, 8000000, 4000000, 2000000, 1000000, 500000, 250000, 125000,
+	}
+)
+
+// CompensateInt compensates a raw measurement with the integer compensation,
+// for targets without FPU. The floating point fields are derived from the
+// integer ones, except when built with the bme68x_fixed tag where they are
+// left zero.
+func CompensateInt(cal Calibration, raw Raw) Measurement {
+	m := raw.measurement()
+
+	temp, tFine := cal.calcTemperatureInt(raw.Temperature)
+
+	m.TemperatureMilli = int32(temp) * 10
+	m.PressurePa = cal.calcPressureInt(raw.Pressure, tFine)
+	m.HumidityMilli = cal.calcHumidityInt(raw.Humidity, tFine)
+
+	// check if gas data is available
+	if m.Status&(HEAT_STAB_MSK|GASM_VALID_MSK) != 0 {
+		if cal.VariantID == VARIANT_GAS_HIGH {
+			m.GasResistanceOhms = cal.calcGasResistanceHighInt(raw.Gas, raw.GasRange)
+		} else {
+			m.GasResistanceOhms = cal.calcGasResistanceLowInt(raw.Gas, raw.GasRange)
+		}
+	}
+
+	// no floating point, which would pull in soft-float
+	if fixedPoint {
+		return m
+	}
+
+	m.TemperatureFine = float32(tFine)
+	m.Temperature = float32(m.TemperatureMilli) * 0.001
+	m.Pressure = float32(m.PressurePa)
+	m.Humidity = float32(m.HumidityMilli) * 0.001
+	m.GasResistance = float32(m.GasResistanceOhms)
+
+	return m
+}
+
+// calcTemperatureInt returns the temperature in 0.01 degree Celsius and the
+// intermediate temperature coefficient used by the other compensations.
+func (c Calibration) calcTemperatureInt(adcTemp uint32) (int16, int32) {
+	var1 := int64(int32(adcTemp)>>3) - (int64(c.T1) << 1)
+	var2 := (var1 * int64(c.T2)) >> 11
+	var3 := ((var1 >> 1) * (var1 >> 1)) >> 12
+	var3 = (var3 * (int64(c.T3) << 4)) >> 14
+	tFine := int32(var2 + var3)
+
+	return int16(((tFine * 5) + 128) >> 8), tFine
+}
+
+// calcPressureInt returns the pressure in Pascal.
+func (c Calibration) calcPressureInt(adcPres uint32, tFine int32) uint32 {
+	const presOvfCheck int32 = 0x40000000
+
+	var1 := (tFine >> 1) - 64000
+	var2 := ((((var1 >> 2) * (var1 >> 2)) >> 11) * int32(c.P6)) >> 2
+	var2 += (var1 * int32(c.P5)) << 1
+	var2 = (var2 >> 2) + (int32(c.P4) << 16)
+	var1 = (((((var1 >> 2) * (var1 >> 2)) >> 13) * (int32(c.P3) << 5)) >> 3) +
+		((int32(c.P2) * var1) >> 1)
+	var1 >>= 18
+	var1 = ((32768 + var1) * int32(c.P1)) >> 15
+
+	// avoid division by zero
+	if var1 == 0 {
+		return 0
+	}
+
+	calcPres := 1048576 - int32(adcPres)
+	calcPres = (calcPres - (var2 >> 12)) * 3125
+
+	if calcPres >= presOvfCheck {
+		calcPres = (calcPres / var1) << 1
+	} else {
+		calcPres = (calcPres << 1) / var1
+	}
+
+	var1 = (int32(c.P9) * (((calcPres >> 3) * (calcPres >> 3)) >> 13)) >> 12
+	var2 = ((calcPres >> 2) * int32(c.P8)) >> 13
+	// 64 bits: the cube times p10 overflows 32 bits above about 100 kPa
+	p := int64(calcPres >> 8)
+	var3 := int32((p * p * p * int64(c.P10)) >> 17)
+
+	return uint32(calcPres + ((var1 + var2 + var3 + (int32(c.P7) << 7)) >> 4))
+}
+
+// calcHumidityInt returns the relative humidity in milli percent.
+func (c Calibration) calcHumidityInt(adcHum uint16, tFine int32) uint32 {
+	tempScaled := ((tFine * 5) + 128) >> 8
+	var1 := (int32(adcHum) - int32(c.H1)*16) -
+		(((tempScaled * int32(c.H3)) / 100) >> 1)
+	var2 := (int32(c.H2) *
+		(((tempScaled * int32(c.H4)) / 100) +
+			(((tempScaled * ((tempScaled * int32(c.H5)) / 100)) >> 6) / 100) +
+			(1 << 14))) >> 10
+	var3 := var1 * var2
+	var4 := int32(c.H6) << 7
+	var4 = (var4 + ((tempScaled * int32(c.H7)) / 100)) >> 4
+	var5 := ((var3 >> 14) * (var3 >> 14)) >> 10
+	var6 := (var4 * var5) >> 1
+	calcHum := (((var3 + var6) >> 10) * 1000) >> 12
+
+	if calcHum > 100000 {
+		return 100000
+	}
+
+	if calcHum < 0 {
+		return 0
+	}
+
+	return uint32(calcHum)
+}
+
+// calcGasResistanceLowInt returns the gas resistance in Ohms of the low gas
+// variant.
+func (c Calibration) calcGasResistanceLowInt(adcGasRes uint16, gasRange uint8) uint32 {
+	var1 := ((1340 + (5 * int64(c.RangeSwErr))) * int64(lookupGasRange1[gasRange])) >> 16
+	var2 := ((int64(adcGasRes) << 15) - 16777216) + var1
+	var3 := (int64(lookupGasRange2[gasRange]) * var1) >> 9
+
+	return uint32((var3 + (var2 >> 1)) / var2)
+}
+
+// calcGasResistanceHighInt returns the gas resistance in Ohms of the high gas
+// variant.
+func (c Calibration) calcGasResistanceHighInt(adcGasRes uint16, gasRange uint8) uint32 {
+	var1 := uint32(262144) >> gasRange
+	var2 := int32(adcGasRes) - 512
+
+	var2 *= 3
+	var2 += 4096
+
+	// multiply by 10000 then by 100 instead of 1000000 to prevent overflow
+	return ((10000 * var1) / uint32(var2)) * 100
+}
+
+// calcResistanceHeatInt calculates the heater resistance value. It takes the
+// target temperature in degree Celsius and returns the calculated heater
+// resistance value.
+func (d *Device) calcResistanceHeatInt(target uint16) uint8 {
+	// cap temperature to 400°C
+	if target > MaxHeatrTemperature {
+		target = MaxHeatrTemperature
+	}
+
+	var1 := ((int32(d.config.AmbientTemperature) * int32(d.calibration.G3)) / 1000) * 256
+	var2 := (int32(d.calibration.G1) + 784) *
+		(((((int32(d.calibration.G2) + 154009) * int32(target) * 5) / 100) + 3276800) / 10)
+	var3 := var1 + (var2 / 2)
+	var4 := var3 / (int32(d.calibration.ResHeatRange) + 4)
+	var5 := (131 * int32(d.calibration.ResHeatVal)) + 65536
+	resHeatX100 := ((var4 / var5) - 250) * 34
+
+	return uint8((resHeatX100 + 50) / 100)
+}
@@ -0,0 +1,83 @@
+package bme68x
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCompensateIntAgrees(t *testing.T) {
+	// absolute accuracies of the BME680 datasheet, the integer gas
+	// resistance of the high variant is rounded down to 100 Ohms
+	const (
+		tempTolerance     = 0.5
+		pressureTolerance = 60
+		humidityTolerance = 3
+		gasTolerance      = 100
+	)
+
+	for i, v := range loadGolden(t).Compensation {
+		for _, variant := range []uint8{0, VARIANT_GAS_HIGH} {
+			t.Run(fmt.Sprintf("%d/variant%d", i, variant), func(t *testing.T) {
+				cal := goldenCalibration(t, v.Calibration)
+				cal.VariantID = variant
+
+				raw := Raw{
+					Status:      NEW_DATA_MSK | GASM_VALID_MSK | HEAT_STAB_MSK,
+					Temperature: v.AdcTemp,
+					Pressure:    v.AdcPres,
+					Humidity:    v.AdcHum,
+					Gas:         v.AdcGas,
+					GasRange:    v.GasRange,
+				}
+
+				f, n := Compensate(cal, raw), CompensateInt(cal, raw)
+
+				for _, c := range []struct {
+					name      string
+					f, n      float32
+					tolerance float64
+				}{
+					{"temperature", f.Temperature, float32(n.TemperatureMilli) / 1000, tempTolerance},
+					{"pressure", f.Pressure, float32(n.PressurePa), pressureTolerance},
+					{"humidity", f.Humidity, float32(n.HumidityMilli) / 1000, humidityTolerance},
+					{"gas resistance", f.GasResistance, float32(n.GasResistanceOhms), gasTolerance + 1e-5*float64(f.GasResistance)},
+				} {
+					if d := math.Abs(float64(c.f - c.n)); d > c.tolerance {
+						t.Errorf("%s: float %v, integer %v, difference %v > %v", c.name, c.f, c.n, d, c.tolerance)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCompensateIntFloatFields(t *testing.T) {
+	v := loadGolden(t).Compensation[0]
+
+	m := CompensateInt(goldenCalibration(t, v.Calibration), Raw{
+		Status:      NEW_DATA_MSK | GASM_VALID_MSK | HEAT_STAB_MSK,
+		Temperature: v.AdcTemp,
+		Pressure:    v.AdcPres,
+		Humidity:    v.AdcHum,
+		Gas:         v.AdcGas,
+		GasRange:    v.GasRange,
+	})
+
+	// no floating point with the bme68x_fixed tag, derived otherwise
+	want := [...]float32{0, 0, 0, 0}
+	if !fixedPoint {
+		want = [...]float32{
+			float32(m.TemperatureMilli) * 0.001, float32(m.PressurePa),
+			float32(m.HumidityMilli) * 0.001, float32(m.GasResistanceOhms),
+		}
+	}
+
+	if got := [...]float32{m.Temperature, m.Pressure, m.Humidity, m.GasResistance}; got != want {
+		t.Errorf("floating point fields %v, want %v", got, want)
+	}
+
+	if m.TemperatureMilli == 0 || m.PressurePa == 0 || m.GasResistanceOhms == 0 {
+		t.Errorf("integer fields of %v not set", m)
+	}
+}
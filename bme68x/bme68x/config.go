@@ -0,0 +1,176 @@
+package bme68x
+
+import (
+	"context"
+	"fmt"
+)
+
+// Validate checks that every setting of the configuration fits the sensor
+// registers, instead of being masked or saturated when applied.
+func (c Config) Validate() error {
+	if err := c.validateOversampling(); err != nil {
+		return err
+	}
+
+	if c.IIR > Coeff128 {
+		return fmt.Errorf("%w: filter coefficient out of range: %d", ErrInvalidConfig, c.IIR)
+	}
+
+	if c.ODR > ODR_NONE {
+		return fmt.Errorf("%w: output data rate out of range: %d", ErrInvalidConfig, c.ODR)
+	}
+
+	if c.mode > ModeSequential {
+		return fmt.Errorf("%w: mode out of range: %d", ErrInvalidConfig, c.mode)
+	}
+
+	if c.HeatrTemp > MaxHeatrTemperature {
+		return fmt.Errorf("%w: heater temperature above %d°C: %d", ErrInvalidConfig, MaxHeatrTemperature, c.HeatrTemp)
+	}
+
+	if c.HeatrDur > MaxHeatrDuration {
+		return fmt.Errorf("%w: heater duration above %dms: %d", ErrInvalidConfig, MaxHeatrDuration, c.HeatrDur)
+	}
+
+	return c.validateHeatrProfile()
+}
+
+// validateOversampling checks the oversampling, which indexes the
+// measurement cycles of measDuration.
+func (c Config) validateOversampling() error {
+	if c.Temperature > Sampling16X {
+		return fmt.Errorf("%w: temperature oversampling out of range: %d", ErrInvalidConfig, c.Temperature)
+	}
+
+	if c.Pressure > Sampling16X {
+		return fmt.Errorf("%w: pressure oversampling out of range: %d", ErrInvalidConfig, c.Pressure)
+	}
+
+	if c.Humidity > Sampling16X {
+		return fmt.Errorf("%w: humidity oversampling out of range: %d", ErrInvalidConfig, c.Humidity)
+	}
+
+	return nil
+}
+
+// validateHeatrProfile checks the heater profile for the operation mode.
+func (c Config) validateHeatrProfile() error {
+	if len(c.HeatrProfile) > MaxHeatrSteps {
+		return fmt.Errorf("%w: heater profile longer than %d steps: %d", ErrInvalidConfig, MaxHeatrSteps, len(c.HeatrProfile))
+	}
+
+	if len(c.HeatrProfile) == 0 {
+		if c.mode == ModeParallel || c.mode == ModeSequential {
+			return fmt.Errorf("%w: heater profile required in mode %d", ErrInvalidConfig, c.mode)
+		}
+
+		return nil
+	}
+
+	for i, step := range c.HeatrProfile {
+		if step.Temp > MaxHeatrTemperature {
+			return fmt.Errorf("%w: heater step %d temperature above %d°C: %d", ErrInvalidConfig, i, MaxHeatrTemperature, step.Temp)
+		}
+
+		switch {
+		case c.mode == ModeParallel && step.Dur > uint16(MaxDuration):
+			return fmt.Errorf("%w: heater step %d multiplier above %d: %d", ErrInvalidConfig, i, MaxDuration, step.Dur)
+		case c.mode != ModeParallel && step.Dur > MaxHeatrDuration:
+			return fmt.Errorf("%w: heater step %d duration above %dms: %d", ErrInvalidConfig, i, MaxHeatrDuration, step.Dur)
+		}
+	}
+
+	if c.mode == ModeParallel {
+		if c.HeatrSharedDur == 0 {
+			return fmt.Errorf("%w: shared heater duration not defined", ErrInvalidConfig)
+		}
+
+		if c.HeatrSharedDur > MaxSharedDuration {
+			return fmt.Errorf("%w: shared heater duration above %dms: %d", ErrInvalidConfig, MaxSharedDuration, c.HeatrSharedDur)
+		}
+	}
+
+	if c.mode == ModeForced && int(c.HeatrStep) >= len(c.HeatrProfile) {
+		return fmt.Errorf("%w: invalid heater step: %d", ErrInvalidConfig, c.HeatrStep)
+	}
+
+	return nil
+}
+
+// SetConfig validates the configuration then applies the oversampling, IIR
+// filter, ODR and heater settings in a single sleep/restore cycle. The
+// operation mode is the one of the configuration, or the current one when the
+// configuration was not returned by Config, so start from Config to keep the
+// other settings. The device configuration is left untouched when the
+// validation or a write fails, the previous settings and mode being written
+// back on a failed write.
+func (d *Device) SetConfig(config Config) error {
+	ctx := context.Background()
+
+	if config.mode == ModeSleep {
+		config.mode = d.config.mode
+	}
+
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	currentMode, err := d.Mode()
+	if err != nil {
+		return fmt.Errorf("failed to read mode: %w", err)
+	}
+
+	// configure only in the sleep mode
+	if err := d.setPowerMode(ctx, ModeSleep); err != nil {
+		if currentMode != ModeSleep {
+			_ = d.setPowerMode(ctx, currentMode)
+		}
+
+		return fmt.Errorf("failed to set sleep mode: %w", err)
+	}
+
+	// do not share the profile with the caller
+	config.HeatrProfile = append([]HeaterStep(nil), config.HeatrProfile...)
+
+	// write from the copy, the device configuration changes only on success
+	saved := d.config
+	d.config = &config
+
+	if err := d.writeConfigs(); err != nil {
+		// rewrite the previous configuration and restore the mode, an error
+		// here leaves the sensor in sleep mode
+		d.config = saved
+
+		if rerr := d.writeConfigs(); rerr == nil && currentMode != ModeSleep {
+			_ = d.setPowerMode(ctx, currentMode)
+		}
+
+		return err
+	}
+
+	*saved = config
+	d.config = saved
+
+	// restore the previous mode, unless the operation mode changed
+	if currentMode != ModeSleep && currentMode == d.config.mode {
+		if err := d.setPowerMode(ctx, currentMode); err != nil {
+			return fmt.Errorf("failed to restore mode: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeConfigs writes the oversampling, filter, ODR and gas configuration.
+// The sensor must be in sleep mode.
+func (d *Device) writeConfigs() error {
+	if err := d.writeConfig(); err != nil {
+		return fmt.Errorf("failed to apply config: %w", err)
+	}
+
+	if err := d.writeGasConfig(); err != nil {
+		return fmt.Errorf("failed to apply gas config: %w", err)
+	}
+
+	return nil
+}
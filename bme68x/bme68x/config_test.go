@@ -0,0 +1,65 @@
+package bme68x_test
+
+import (
+	"errors"
+	"testing"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/emulator"
+)
+
+var errBus = errors.New("bus error")
+
+// failingI2C fails the first write to the register reg.
+type failingI2C struct {
+	*emulator.I2C
+	reg    uint8
+	failed bool
+}
+
+func (b *failingI2C) Tx(addr uint16, w, r []byte) error {
+	if !b.failed && len(w) > 1 && w[0] == b.reg {
+		b.failed = true
+		return errBus
+	}
+
+	return b.I2C.Tx(addr, w, r)
+}
+
+func TestSetConfigFailure(t *testing.T) {
+	e := emulator.New(emulator.WithVariant(bme68x.VARIANT_GAS_HIGH))
+	bus := &failingI2C{I2C: e.I2C(), reg: bme68x.REG_CTRL_GAS_0, failed: true}
+
+	d := bme68x.NewI2C(bus, bme68x.WithHeatrDuration(2))
+	if err := d.Configure(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.SetHeaterProfile([]bme68x.HeaterStep{{Temp: 320, Dur: 5}, {Temp: 200, Dur: 5}}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.SetMode(bme68x.ModeSequential); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := d.Config()
+	ctrlMeas := e.Register(bme68x.REG_CTRL_MEAS)
+
+	// the oversampling is written, then the gas configuration fails
+	config := d.Config()
+	config.Temperature = bme68x.Sampling1X
+	bus.failed = false
+
+	if err := d.SetConfig(config); !errors.Is(err, errBus) {
+		t.Fatalf("SetConfig returned %v, want %v", err, errBus)
+	}
+
+	if got := d.Config(); got.Temperature != saved.Temperature {
+		t.Errorf("config %v after a failed SetConfig, want %v", got, saved)
+	}
+
+	if got := e.Register(bme68x.REG_CTRL_MEAS); got != ctrlMeas {
+		t.Errorf("ctrl_meas 0x%02X after a failed SetConfig, want 0x%02X", got, ctrlMeas)
+	}
+}
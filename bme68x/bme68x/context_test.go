@@ -0,0 +1,37 @@
+package bme68x_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"BME68x/bme68x/emulator"
+)
+
+func TestContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, bus := range emulator.Buses {
+		t.Run(bus.Name, func(t *testing.T) {
+			d := bus.New(emulator.New())
+
+			if err := d.ConfigureContext(ctx); !errors.Is(err, context.Canceled) {
+				t.Fatalf("ConfigureContext returned %v, want %v", err, context.Canceled)
+			}
+
+			if err := d.Configure(); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := d.MeasureContext(ctx); !errors.Is(err, context.Canceled) {
+				t.Fatalf("MeasureContext returned %v, want %v", err, context.Canceled)
+			}
+
+			// the canceled measurement does not get in the way of the next one
+			if _, err := d.Measure(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Package derived computes psychrometric and comfort metrics from the
+// temperature and relative humidity measured by a BME68x sensor. Every
+// function is pure, the temperatures are in degree Celsius and the relative
+// humidities in percent.
+package derived
+
+import (
+	"math"
+	"strings"
+)
+
+// Magnus formula coefficients over water (Sonntag 1990), valid from -45°C to
+// 60°C.
+const (
+	magnusA = 17.62
+	magnusB = 243.12
+	// magnusC is the saturation vapor pressure at 0°C in hPa.
+	magnusC = 6.112
+)
+
+// SaturationVaporPressure returns the saturation vapor pressure over water in
+// hPa.
+func SaturationVaporPressure(temp float32) float32 {
+	return float32(saturationVaporPressure(float64(temp)))
+}
+
+func saturationVaporPressure(t float64) float64 {
+	return magnusC * math.Exp(magnusA*t/(magnusB+t))
+}
+
+// DewPoint returns the dew point, the temperature at which the air would be
+// saturated. It returns -Inf for a null humidity.
+func DewPoint(temp, humidity float32) float32 {
+	if humidity <= 0 {
+		return float32(math.Inf(-1))
+	}
+
+	t, rh := float64(temp), float64(humidity)
+
+	gamma := math.Log(rh/100) + magnusA*t/(magnusB+t)
+
+	return float32(magnusB * gamma / (magnusA - gamma))
+}
+
+// AbsoluteHumidity returns the mass of water vapor in the air in g/m³.
+func AbsoluteHumidity(temp, humidity float32) float32 {
+	t, rh := float64(temp), float64(humidity)
+
+	// vapor pressure in Pa over the specific gas constant of water vapor,
+	// 461.5 J/(kg·K)
+	e := saturationVaporPressure(t) * rh // hPa * 100 / 100
+	return float32(e / (461.5 * (t + 273.15)) * 1000)
+}
+
+// VaporPressureDeficit returns the difference between the saturation and the
+// actual vapor pressures in kPa.
+func VaporPressureDeficit(temp, humidity float32) float32 {
+	svp := saturationVaporPressure(float64(temp)) / 10
+
+	return float32(svp * (1 - float64(humidity)/100))
+}
+
+// HeatIndex returns the apparent temperature felt in the shade, with the
+// regression of the US National Weather Service. Below about 27°C it is close
+// to the temperature.
+func HeatIndex(temp, humidity float32) float32 {
+	f, rh := float64(temp)*9/5+32, float64(humidity)
+
+	// simple formula, accurate enough below 80°F
+	hi := 0.5 * (f + 61 + (f-68)*1.2 + rh*0.094)
+
+	if (hi+f)/2 >= 80 {
+		hi = -42.379 + 2.04901523*f + 10.14333127*rh -
+			0.22475541*f*rh - 0.00683783*f*f - 0.05481717*rh*rh +
+			0.00122874*f*f*rh + 0.00085282*f*rh*rh - 0.00000199*f*f*rh*rh
+
+		switch {
+		case rh < 13 && f >= 80 && f <= 112:
+			hi -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(f-95))/17)
+		case rh > 85 && f >= 80 && f <= 87:
+			hi += (rh - 85) / 10 * (87 - f) / 5
+		}
+	}
+
+	return float32((hi - 32) * 5 / 9)
+}
+
+// Humidex returns the humidity index of the Meteorological Service of
+// Canada, the temperature felt by the average person.
+func Humidex(temp, humidity float32) float32 {
+	// vapor pressure in hPa, finite down to a null humidity unlike the dew
+	// point of the MSC formula
+	e := saturationVaporPressure(float64(temp)) * float64(humidity) / 100
+
+	return temp + float32(0.5555*(e-10))
+}
+
+// Comfort is a comfort classification. The zero value is comfortable,
+// otherwise it holds the reasons of the discomfort.
+type Comfort uint8
+
+// Comfortable is within the comfort zone.
+const Comfortable Comfort = 0
+
+const (
+	// ComfortCold is below MinTemperature.
+	ComfortCold Comfort = 1 << iota
+	// ComfortHot is above MaxTemperature.
+	ComfortHot
+	// ComfortDry is below MinHumidity.
+	ComfortDry
+	// ComfortHumid is above MaxHumidity.
+	ComfortHumid
+)
+
+// Bounds of the comfort zone of Classify.
+const (
+	MinTemperature = 18
+	MaxTemperature = 26
+	MinHumidity    = 30
+	MaxHumidity    = 60
+)
+
+var comfortNames = [...]struct {
+	comfort Comfort
+	name    string
+}{
+	{ComfortCold, "cold"},
+	{ComfortHot, "hot"},
+	{ComfortDry, "dry"},
+	{ComfortHumid, "humid"},
+}
+
+// Classify returns the comfort classification of the temperature and
+// relative humidity, in the zone from MinTemperature to MaxTemperature and
+// from MinHumidity to MaxHumidity.
+func Classify(temp, humidity float32) Comfort {
+	var c Comfort
+
+	switch {
+	case temp < MinTemperature:
+		c |= ComfortCold
+	case temp > MaxTemperature:
+		c |= ComfortHot
+	}
+
+	switch {
+	case humidity < MinHumidity:
+		c |= ComfortDry
+	case humidity > MaxHumidity:
+		c |= ComfortHumid
+	}
+
+	return c
+}
+
+// String implements fmt.Stringer interface, such as "comfortable" or
+// "hot, humid".
+func (c Comfort) String() string {
+	if c == Comfortable {
+		return "comfortable"
+	}
+
+	var names []string
+	for _, n := range comfortNames {
+		if c&n.comfort != 0 {
+			names = append(names, n.name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
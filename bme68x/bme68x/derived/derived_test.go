@@ -0,0 +1,101 @@
+package derived
+
+import (
+	"math"
+	"testing"
+)
+
+// fahrenheit returns the temperature in degree Celsius.
+func fahrenheit(f float32) float32 {
+	return (f - 32) * 5 / 9
+}
+
+func TestFunctions(t *testing.T) {
+	for _, c := range []struct {
+		name           string
+		f              func(temp, humidity float32) float32
+		temp, humidity float32
+		want, tol      float32
+	}{
+		// Magnus formula, as tabulated by Sonntag 1990
+		{"DewPoint", DewPoint, 25, 60, 16.7, 0.1},
+		{"DewPoint", DewPoint, 20, 50, 9.3, 0.1},
+		{"DewPoint", DewPoint, -10, 80, -12.8, 0.1},
+		{"DewPoint", DewPoint, 30, 100, 30, 1e-3},
+		{"DewPoint", DewPoint, 25, 0, float32(math.Inf(-1)), 0},
+		{"AbsoluteHumidity", AbsoluteHumidity, 20, 100, 17.3, 0.1},
+		{"AbsoluteHumidity", AbsoluteHumidity, 20, 0, 0, 0},
+		{"VaporPressureDeficit", VaporPressureDeficit, 25, 60, 1.27, 0.01},
+		{"VaporPressureDeficit", VaporPressureDeficit, 25, 100, 0, 0},
+		// NWS heat index chart, in degree Fahrenheit
+		{"HeatIndex", HeatIndex, fahrenheit(80), 40, fahrenheit(80), 0.6},
+		{"HeatIndex", HeatIndex, fahrenheit(90), 70, fahrenheit(106), 0.6},
+		{"HeatIndex", HeatIndex, fahrenheit(100), 50, fahrenheit(118), 0.6},
+		{"HeatIndex", HeatIndex, fahrenheit(86), 90, fahrenheit(105), 0.6},
+		// MSC humidex table, 30°C with a dew point of 15°C being 34
+		{"Humidex", Humidex, 30, 40.2, 34, 0.5},
+		{"Humidex", Humidex, 30, 70, 41, 0.5},
+		{"Humidex", Humidex, 35, 50, 45, 0.5},
+		{"Humidex", Humidex, 25, 0, 25 - 0.5555*10, 1e-3},
+		{"Humidex", Humidex, 25, 100, 37, 0.5},
+	} {
+		got := c.f(c.temp, c.humidity)
+
+		if math.IsInf(float64(c.want), 0) {
+			if got != c.want {
+				t.Errorf("%s(%v, %v) = %v, want %v", c.name, c.temp, c.humidity, got, c.want)
+			}
+
+			continue
+		}
+
+		if math.IsNaN(float64(got)) || math.Abs(float64(got-c.want)) > float64(c.tol) {
+			t.Errorf("%s(%v, %v) = %v, want %v ± %v", c.name, c.temp, c.humidity, got, c.want, c.tol)
+		}
+	}
+}
+
+func TestSaturationVaporPressure(t *testing.T) {
+	for _, c := range []struct {
+		temp, want float32
+	}{
+		{0, 6.112},
+		{20, 23.37},
+		{30, 42.46},
+		{-20, 1.254},
+	} {
+		// within 0.5% of the Goff-Gratch values
+		if got := SaturationVaporPressure(c.temp); math.Abs(float64(got/c.want-1)) > 0.005 {
+			t.Errorf("SaturationVaporPressure(%v) = %v, want %v", c.temp, got, c.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	for _, c := range []struct {
+		temp, humidity float32
+		want           Comfort
+		name           string
+	}{
+		{22, 45, Comfortable, "comfortable"},
+		{MinTemperature, MinHumidity, Comfortable, "comfortable"},
+		{MaxTemperature, MaxHumidity, Comfortable, "comfortable"},
+		{15, 45, ComfortCold, "cold"},
+		{30, 70, ComfortHot | ComfortHumid, "hot, humid"},
+		{15, 20, ComfortCold | ComfortDry, "cold, dry"},
+	} {
+		got := Classify(c.temp, c.humidity)
+		if got != c.want || got.String() != c.name {
+			t.Errorf("Classify(%v, %v) = %v (%d), want %v (%d)", c.temp, c.humidity, got, got, c.name, c.want)
+		}
+	}
+}
+
+func TestComfortFlags(t *testing.T) {
+	// the flags start at bit 0, each on its own bit
+	for i, c := range []Comfort{ComfortCold, ComfortHot, ComfortDry, ComfortHumid} {
+		if c != 1<<i {
+			t.Errorf("flag %v is %#x, want %#x", c, uint8(c), 1<<i)
+		}
+	}
+}
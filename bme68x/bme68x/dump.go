@@ -0,0 +1,212 @@
+package bme68x
+
+import (
+	"fmt"
+	"io"
+)
+
+// RegisterState is a register read by ReadRegisters, with the value expected
+// from the configuration.
+type RegisterState struct {
+	Register
+	Value uint8
+	// Expected is the value derived from the configuration, when Checked.
+	Expected uint8
+	Checked  bool
+}
+
+// Mismatch reports whether the value differs from the expected one.
+func (s RegisterState) Mismatch() bool {
+	return s.Checked && s.Value != s.Expected
+}
+
+// String implements fmt.Stringer interface.
+func (s RegisterState) String() string {
+	if s.Mismatch() {
+		return fmt.Sprintf("%s, expected %s", s.Format(s.Value), s.Format(s.Expected))
+	}
+
+	return s.Format(s.Value)
+}
+
+// RegisterDump is the content of the registers of the sensor, read by
+// ReadRegisters.
+type RegisterDump struct {
+	// Memory holds both SPI memory pages, indexed by I2C address.
+	Memory [256]byte
+	// Registers are the registers of Registers, decoded from Memory.
+	Registers []RegisterState
+	// Calibration is decoded from the calibration blocks in Memory.
+	Calibration Calibration
+	// Fields are the field buffers decoded from Memory.
+	Fields [N_FIELDS]Raw
+}
+
+// ReadRegisters reads every register of the sensor, and compares the control
+// and heater registers with the values written for the configuration. The
+// mode field is not compared, as the sensor returns to sleep after a forced
+// measurement.
+func (d *Device) ReadRegisters() (RegisterDump, error) {
+	var dump RegisterDump
+
+	// a read can not cross the SPI memory pages
+	for _, reg := range []uint8{0x00, 0x80} {
+		if err := d.bus.Read(d.address, reg, dump.Memory[reg:int(reg)+0x80]); err != nil {
+			return dump, fmt.Errorf("failed to read registers 0x%02X: %w", reg, err)
+		}
+	}
+
+	mem := &dump.Memory
+
+	var coeff [LEN_COEFF_ALL]byte
+	copy(coeff[:], mem[REG_COEFF1:REG_COEFF1+LEN_COEFF1])
+	copy(coeff[LEN_COEFF1:], mem[REG_COEFF2:REG_COEFF2+LEN_COEFF2])
+	copy(coeff[LEN_COEFF1+LEN_COEFF2:], mem[REG_COEFF3:REG_COEFF3+LEN_COEFF3])
+
+	dump.Calibration = parseCalibration(coeff)
+	dump.Calibration.VariantID = mem[REG_VARIANT_ID]
+
+	for i := range dump.Fields {
+		var field [LEN_FIELD]byte
+
+		start := MEAS_STATUS_0 + uint8(i)*LEN_FIELD
+		copy(field[:], mem[start:start+LEN_FIELD])
+
+		dump.Fields[i] = parseRaw(field, d.VariantID)
+	}
+
+	expected, checked, err := d.expectedRegisters(mem)
+	if err != nil {
+		return dump, err
+	}
+
+	dump.Registers = make([]RegisterState, len(Registers))
+	for i, reg := range Registers {
+		dump.Registers[i] = RegisterState{
+			Register: reg,
+			Value:    mem[reg.Addr],
+			Expected: expected[reg.Addr],
+			Checked:  checked[reg.Addr],
+		}
+	}
+
+	return dump, nil
+}
+
+// expectedRegisters returns the registers expected from the configuration,
+// starting from the content of mem, and which of them are checked.
+func (d *Device) expectedRegisters(mem *[256]byte) ([256]byte, [256]bool, error) {
+	var (
+		expected = *mem
+		checked  [256]bool
+	)
+
+	var resHeat, gasWait [MaxHeatrSteps]uint8
+
+	n, nbConv, err := d.heatrRegisters(resHeat[:], gasWait[:])
+	if err != nil {
+		return expected, checked, err
+	}
+
+	for i := uint8(0); i < n; i++ {
+		expected[REG_RES_HEAT0+i], checked[REG_RES_HEAT0+i] = resHeat[i], true
+		expected[REG_GAS_WAIT0+i], checked[REG_GAS_WAIT0+i] = gasWait[i], true
+	}
+
+	if d.config.mode == ModeParallel {
+		expected[REG_SHD_HEATR_DUR] = calcHeatrDurShared(d.config.HeatrSharedDur)
+		checked[REG_SHD_HEATR_DUR] = true
+	}
+
+	d.setGasFields(expected[REG_CTRL_GAS_0:REG_CTRL_GAS_1+1], nbConv)
+	d.setConfigFields(expected[REG_CTRL_GAS_1 : REG_CONFIG+1])
+
+	// the mode is not part of the expected state
+	ctrlMeas := (*regCtrlMeas)(&expected[REG_CTRL_MEAS])
+	ctrlMeas.SetMode(regCtrlMeas(mem[REG_CTRL_MEAS]).Mode())
+
+	for _, reg := range []uint8{REG_CTRL_GAS_0, REG_CTRL_GAS_1, REG_CTRL_HUM, REG_CTRL_MEAS, REG_CONFIG} {
+		checked[reg] = true
+	}
+
+	expected[REG_CHIP_ID], checked[REG_CHIP_ID] = CHIP_ID, true
+	expected[REG_VARIANT_ID], checked[REG_VARIANT_ID] = d.VariantID, true
+
+	return expected, checked, nil
+}
+
+// Diff returns the registers differing from the expected values.
+func (r RegisterDump) Diff() []RegisterState {
+	var diff []RegisterState
+
+	for _, reg := range r.Registers {
+		if reg.Mismatch() {
+			diff = append(diff, reg)
+		}
+	}
+
+	return diff
+}
+
+// WriteTo implements io.WriterTo interface. It writes the memory in
+// hexadecimal, the decoded registers, the calibration, the field buffers and
+// the registers differing from the configuration.
+func (r RegisterDump) WriteTo(w io.Writer) (int64, error) {
+	ew := &errWriter{w: w}
+
+	ew.printf("memory:\n")
+	for addr := 0; addr < len(r.Memory); addr += 16 {
+		ew.printf("0x%02X: % X\n", addr, r.Memory[addr:addr+16])
+	}
+
+	ew.printf("registers:\n")
+	for _, reg := range r.Registers {
+		ew.printf("  %s\n", reg)
+	}
+
+	ew.printf("calibration: %s\n", r.Calibration)
+
+	for i, field := range r.Fields {
+		ew.printf("field %d: %s\n", i, field)
+	}
+
+	diff := r.Diff()
+
+	ew.printf("diff: %d register(s) differ from the configuration\n", len(diff))
+	for _, reg := range diff {
+		ew.printf("  %s\n", reg)
+	}
+
+	return ew.n, ew.err
+}
+
+// Dump reads every register of the sensor and writes them decoded to w, with
+// the registers differing from the configuration. Use ReadRegisters for the
+// structured form.
+func (d *Device) Dump(w io.Writer) error {
+	dump, err := d.ReadRegisters()
+	if err != nil {
+		return err
+	}
+
+	_, err = dump.WriteTo(w)
+
+	return err
+}
+
+// errWriter writes formatted text until the first error.
+type errWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(ew.w, format, args...)
+	ew.n += int64(n)
+	ew.err = err
+}
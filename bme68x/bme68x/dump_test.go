@@ -0,0 +1,85 @@
+package bme68x_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/emulator"
+)
+
+func TestReadRegisters(t *testing.T) {
+	for _, bus := range emulator.Buses {
+		t.Run(bus.Name, func(t *testing.T) {
+			e := emulator.New(emulator.WithVariant(bme68x.VARIANT_GAS_HIGH))
+			d := bus.New(e, bme68x.WithHeatrDuration(2))
+
+			if err := d.Configure(); err != nil {
+				t.Fatal(err)
+			}
+
+			dump, err := d.ReadRegisters()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// both pages, page 1 being read at 0x80 over SPI, where the
+			// status register shadows 0x73
+			for addr := range dump.Memory {
+				if bus.Name == "SPI" && addr == int(bme68x.REG_MEM_PAGE&bme68x.SPI_WR_MSK) {
+					continue
+				}
+
+				if got, want := dump.Memory[addr], e.Register(uint8(addr)); got != want {
+					t.Errorf("memory 0x%02X = 0x%02X, want 0x%02X", addr, got, want)
+				}
+			}
+
+			if dump.Calibration != d.Calibration() {
+				t.Errorf("calibration %v, want %v", dump.Calibration, d.Calibration())
+			}
+
+			if diff := dump.Diff(); len(diff) != 0 {
+				t.Errorf("diff %v after Configure, want none", diff)
+			}
+
+			// change the humidity oversampling behind the driver
+			ctrlHum := e.Register(bme68x.REG_CTRL_HUM)
+			if err := e.I2C().Tx(bme68x.Address, []byte{bme68x.REG_CTRL_HUM, ctrlHum ^ 0x07}, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if dump, err = d.ReadRegisters(); err != nil {
+				t.Fatal(err)
+			}
+
+			diff := dump.Diff()
+			if len(diff) != 1 || diff[0].Addr != bme68x.REG_CTRL_HUM ||
+				diff[0].Value != ctrlHum^0x07 || diff[0].Expected != ctrlHum {
+				t.Fatalf("diff %v, want ctrl_hum 0x%02X, expected 0x%02X", diff, ctrlHum^0x07, ctrlHum)
+			}
+
+			var buf bytes.Buffer
+			if err := d.Dump(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			out := buf.String()
+			for _, want := range []string{
+				fmt.Sprintf("0x80: % X", dump.Memory[0x80:0x90]),
+				"chip_id (0xD0) = 0x61",
+				diff[0].String(),
+				"osrs_h=",
+				"calibration: ",
+				"field 2: ",
+				"diff: 1 register(s) differ from the configuration",
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("dump without %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
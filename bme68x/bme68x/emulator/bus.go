@@ -0,0 +1,22 @@
+package emulator
+
+import "BME68x/bme68x"
+
+// Bus is a bus of the emulator, to run the same test over I2C and SPI.
+type Bus struct {
+	// Name is "I2C" or "SPI".
+	Name string
+	// New returns a driver on the bus of the emulated device.
+	New func(d *Device, opts ...bme68x.Option) *bme68x.Device
+}
+
+// Buses are the I2C and SPI buses of the emulator.
+var Buses = []Bus{
+	{"I2C", func(d *Device, opts ...bme68x.Option) *bme68x.Device {
+		return bme68x.NewI2C(d.I2C(), opts...)
+	}},
+	{"SPI", func(d *Device, opts ...bme68x.Option) *bme68x.Device {
+		s := d.SPI()
+		return bme68x.NewSPI(s, s.CS(), opts...)
+	}},
+}
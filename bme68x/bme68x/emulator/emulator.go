@@ -0,0 +1,362 @@
+// Package emulator provides a register-level emulator of the BME680/BME688
+// sensor. It implements drivers.I2C and drivers.SPI, so the bme68x driver can
+// run end-to-end on a host without hardware.
+//
+// The emulator models the chip and variant IDs, the calibration blocks, the
+// control registers, the three field buffers, the soft reset and the SPI
+// memory page. Measurements complete after the duration computed from the
+// oversampling and heater settings, in forced, parallel and sequential mode.
+package emulator
+
+import (
+	"sync"
+	"time"
+
+	"BME68x/bme68x"
+)
+
+const (
+	// LenCalibration is the length of the calibration image, made of the
+	// REG_COEFF1, REG_COEFF2 and REG_COEFF3 blocks.
+	LenCalibration = 42
+
+	lenCoeff1 = 23
+	lenCoeff2 = 14
+
+	// regStatus is the SPI status register holding the memory page
+	regStatus uint8 = 0x73
+	// idacHeat is the default heater current DAC reached by the heater control
+	idacHeat uint8 = 0x42
+	// skipped is the value of a skipped 20-bit measurement
+	skipped uint32 = 0x80000
+	// maxCatchUp is the maximum number of measurements completed at once
+	maxCatchUp = bme68x.N_FIELDS * bme68x.MaxHeatrSteps
+)
+
+var (
+	osToMeasCycles = [8]uint32{0, 1, 2, 4, 8, 16, 16, 16}
+
+	// defaultCalibration is the calibration image of a typical BME680.
+	defaultCalibration = [LenCalibration]byte{
+		0x2B, 0x67, 0x03, 0x00, 0x14, 0x8E, 0x0B, 0xD7, 0x58, 0x00, 0xFB, 0x1C,
+		0x78, 0xFF, 0x2C, 0x1E, 0x00, 0x00, 0x92, 0xF4, 0xC8, 0xF7, 0x1E,
+		0x40, 0x39, 0x33, 0x00, 0x2D, 0x14, 0x78, 0x9C, 0x79, 0x66, 0x80, 0xD8,
+		0xCE, 0x12,
+		0x28, 0x00, 0x10, 0x00, 0xF0,
+	}
+
+	// defaultADC is about 25°C, 1000hPa and 34%RH with the default calibration.
+	defaultADC = ADC{
+		Temperature: 500000,
+		Pressure:    350000,
+		Humidity:    20000,
+		Gas:         600,
+		GasRange:    5,
+	}
+)
+
+type (
+	// ADC holds the raw values reported by the emulated sensor.
+	ADC struct {
+		Temperature uint32
+		Pressure    uint32
+		Humidity    uint16
+		Gas         uint16
+		GasRange    uint8
+	}
+
+	// Device is an emulated BME680/BME688 sensor.
+	Device struct {
+		mu      sync.Mutex
+		address uint16
+		variant uint8
+		now     func() time.Time
+		spi     *SPI
+		idac    uint8
+
+		regs [256]byte
+		// page is the SPI memory page bit of the status register
+		page uint8
+		adc  ADC
+
+		// measEnd is the end of the running measurement, zero when sleeping
+		measEnd   time.Time
+		measIndex uint8
+		// field is the next field buffer in parallel and sequential mode
+		field uint8
+		// step is the heater step of the running measurement
+		step uint8
+		// cycle is the measurement cycle within the heater step in parallel mode
+		cycle uint8
+	}
+)
+
+// New creates an emulated sensor, in the state following a power-on reset.
+func New(opts ...Option) *Device {
+	d := &Device{
+		address: bme68x.Address,
+		now:     time.Now,
+		adc:     defaultADC,
+		idac:    idacHeat,
+	}
+
+	d.spi = &SPI{device: d, cs: Pin{high: true}}
+
+	d.setCalibration(defaultCalibration)
+
+	for _, option := range opts {
+		option(d)
+	}
+
+	d.regs[bme68x.REG_CHIP_ID] = bme68x.CHIP_ID
+	d.regs[bme68x.REG_VARIANT_ID] = d.variant
+
+	return d
+}
+
+// I2C returns the I2C bus connected to the emulated sensor.
+func (d *Device) I2C() *I2C {
+	return &I2C{device: d}
+}
+
+// SPI returns the SPI bus connected to the emulated sensor, the same bus on
+// each call.
+func (d *Device) SPI() *SPI {
+	return d.spi
+}
+
+// SetADC sets the raw values reported by the following measurements.
+func (d *Device) SetADC(adc ADC) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.adc = adc
+}
+
+// Register returns the content of a register, as seen over I2C.
+func (d *Device) Register(reg uint8) byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.update()
+
+	return d.regs[reg]
+}
+
+func (d *Device) setCalibration(data [LenCalibration]byte) {
+	copy(d.regs[bme68x.REG_COEFF1:], data[:lenCoeff1])
+	copy(d.regs[bme68x.REG_COEFF2:], data[lenCoeff1:lenCoeff1+lenCoeff2])
+	copy(d.regs[bme68x.REG_COEFF3:], data[lenCoeff1+lenCoeff2:])
+}
+
+// read reads consecutive registers starting at reg.
+func (d *Device) read(reg uint8, data []byte) {
+	d.update()
+
+	for i := range data {
+		data[i] = d.regs[reg+uint8(i)]
+	}
+}
+
+// write writes a register. Read-only registers are left untouched, like on
+// the sensor.
+func (d *Device) write(reg, value uint8) {
+	d.update()
+
+	switch {
+	case reg == bme68x.REG_SOFT_RESET:
+		if value == bme68x.CMD_RESET {
+			d.reset()
+		}
+	case reg == bme68x.REG_CTRL_MEAS:
+		d.regs[reg] = value
+		d.start(bme68x.Mode(value & bme68x.MODE_MSK))
+	case reg >= bme68x.REG_IDAC_HEAT0 && reg <= bme68x.REG_CONFIG:
+		d.regs[reg] = value
+	}
+}
+
+// reset restores the control, heater and field registers.
+func (d *Device) reset() {
+	for reg := bme68x.MEAS_STATUS_0; reg <= bme68x.REG_CONFIG; reg++ {
+		d.regs[reg] = 0
+	}
+
+	d.page = 0
+	d.measEnd = time.Time{}
+	d.measIndex = 0
+	d.field = 0
+	d.step = 0
+	d.cycle = 0
+}
+
+// start starts the measurements of the mode written to ctrl_meas.
+func (d *Device) start(mode bme68x.Mode) {
+	now := d.now()
+
+	if mode == bme68x.ModeSleep {
+		d.measEnd = time.Time{}
+		return
+	}
+
+	// new_data is cleared when a conversion starts
+	for i := uint8(0); i < bme68x.N_FIELDS; i++ {
+		d.regs[bme68x.MEAS_STATUS_0+i*bme68x.LEN_FIELD] &^= bme68x.NEW_DATA_MSK
+	}
+
+	d.field = 0
+	d.step = 0
+	d.cycle = 0
+
+	// in forced mode nb_conv selects the heater step
+	if mode == bme68x.ModeForced {
+		d.step = d.regs[bme68x.REG_CTRL_GAS_1] & bme68x.NBCONV_MSK
+	}
+
+	d.measEnd = now.Add(d.measDuration(mode, d.step))
+}
+
+// update completes the measurements whose duration elapsed.
+func (d *Device) update() {
+	if d.measEnd.IsZero() {
+		return
+	}
+
+	now := d.now()
+	mode := bme68x.Mode(d.regs[bme68x.REG_CTRL_MEAS] & bme68x.MODE_MSK)
+
+	if mode == bme68x.ModeForced {
+		if !now.Before(d.measEnd) {
+			d.store()
+			d.regs[bme68x.REG_CTRL_MEAS] &^= bme68x.MODE_MSK
+			d.measEnd = time.Time{}
+		}
+
+		return
+	}
+
+	for i := 0; !now.Before(d.measEnd); i++ {
+		// skip ahead after a long pause, only the last fields are visible anyway
+		if i == maxCatchUp {
+			d.measEnd = now.Add(d.measDuration(mode, d.step))
+			break
+		}
+
+		d.store()
+		d.field = (d.field + 1) % bme68x.N_FIELDS
+
+		// in parallel mode a heater step lasts gas_wait_x measurement cycles
+		d.cycle++
+		if mode != bme68x.ModeParallel || d.cycle >= d.regs[bme68x.REG_GAS_WAIT0+d.step] {
+			nbConv := d.regs[bme68x.REG_CTRL_GAS_1] & bme68x.NBCONV_MSK
+
+			d.cycle = 0
+			d.step++
+			if nbConv == 0 || d.step >= nbConv {
+				d.step = 0
+			}
+		}
+
+		d.measEnd = d.measEnd.Add(d.measDuration(mode, d.step))
+	}
+}
+
+// store writes the result of the running measurement to the current field.
+func (d *Device) store() {
+	var (
+		field  = d.regs[bme68x.MEAS_STATUS_0+d.field*bme68x.LEN_FIELD:][:bme68x.LEN_FIELD]
+		ctrl   = d.regs[bme68x.REG_CTRL_MEAS]
+		hum    = uint32(d.adc.Humidity)
+		temp   = d.adc.Temperature
+		pres   = d.adc.Pressure
+		gasReg = 13
+	)
+
+	for i := range field {
+		field[i] = 0
+	}
+
+	if (ctrl>>bme68x.OST_POS)&0x07 == 0 {
+		temp = skipped
+	}
+
+	if (ctrl>>bme68x.OSP_POS)&0x07 == 0 {
+		pres = skipped
+	}
+
+	if d.regs[bme68x.REG_CTRL_HUM]&bme68x.OSH_MSK == 0 {
+		hum = 0x8000
+	}
+
+	field[0] = bme68x.NEW_DATA_MSK | (d.step & bme68x.GAS_INDEX_MSK)
+	field[1] = d.measIndex
+	field[2], field[3], field[4] = byte(pres>>12), byte(pres>>4), byte(pres<<4)
+	field[5], field[6], field[7] = byte(temp>>12), byte(temp>>4), byte(temp<<4)
+	field[8], field[9] = byte(hum>>8), byte(hum)
+
+	if d.variant == bme68x.VARIANT_GAS_HIGH {
+		gasReg = 15
+	}
+
+	if d.runGas() {
+		status := bme68x.GASM_VALID_MSK
+
+		// the heater is stable when enabled with a target and a duration
+		if d.regs[bme68x.REG_CTRL_GAS_0]&bme68x.HCTRL_MSK == 0 &&
+			d.regs[bme68x.REG_RES_HEAT0+d.step] != 0 && d.regs[bme68x.REG_GAS_WAIT0+d.step] != 0 {
+			status |= bme68x.HEAT_STAB_MSK
+			d.regs[bme68x.REG_IDAC_HEAT0+d.step] = d.idac
+		}
+
+		field[gasReg] = byte(d.adc.Gas >> 2)
+		field[gasReg+1] = byte(d.adc.Gas<<6) | status | (d.adc.GasRange & bme68x.GAS_RANGE_MSK)
+	}
+
+	d.measIndex++
+}
+
+// runGas reports whether the gas measurement is enabled for the variant.
+func (d *Device) runGas() bool {
+	runGas := (d.regs[bme68x.REG_CTRL_GAS_1] & bme68x.RUN_GAS_MSK) >> bme68x.RUN_GAS_POS
+
+	if d.variant == bme68x.VARIANT_GAS_HIGH {
+		return runGas == bme68x.ENABLE_GAS_MEAS_H
+	}
+
+	return runGas == bme68x.ENABLE_GAS_MEAS_L
+}
+
+// measDuration returns the duration of a measurement using the heater step.
+func (d *Device) measDuration(mode bme68x.Mode, step uint8) time.Duration {
+	ctrl := d.regs[bme68x.REG_CTRL_MEAS]
+
+	measCycles := osToMeasCycles[(ctrl>>bme68x.OST_POS)&0x07]
+	measCycles += osToMeasCycles[(ctrl>>bme68x.OSP_POS)&0x07]
+	measCycles += osToMeasCycles[d.regs[bme68x.REG_CTRL_HUM]&bme68x.OSH_MSK]
+
+	// TPH measurement duration in µs
+	dur := time.Duration(measCycles*bme68x.MeasOffset+bme68x.MeasDur) * time.Microsecond
+
+	if mode != bme68x.ModeParallel {
+		dur += bme68x.WakeUpDur * time.Microsecond
+	}
+
+	if !d.runGas() {
+		return dur
+	}
+
+	dur += time.Duration(bme68x.GasDur) * time.Microsecond
+
+	// the shared heater duration has a step size of 0.477ms
+	if mode == bme68x.ModeParallel {
+		return dur + decodeDuration(d.regs[bme68x.REG_SHD_HEATR_DUR])*477/1000
+	}
+
+	return dur + decodeDuration(d.regs[bme68x.REG_GAS_WAIT0+step])
+}
+
+// decodeDuration decodes a gas_wait or shd_heatr_dur register value, made of
+// a 6-bit value and a 2-bit multiplication factor of 1, 4, 16 or 64.
+func decodeDuration(value uint8) time.Duration {
+	return time.Duration(uint32(value&0x3F)<<(2*(value>>6))) * time.Millisecond
+}
@@ -0,0 +1,153 @@
+package emulator_test
+
+import (
+	"errors"
+	"testing"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/emulator"
+)
+
+var adc = emulator.ADC{
+	Temperature: 500000,
+	Pressure:    350000,
+	Humidity:    20000,
+	Gas:         600,
+	GasRange:    5,
+}
+
+func TestEndToEnd(t *testing.T) {
+	for _, variant := range []struct {
+		name string
+		id   uint8
+	}{
+		{"BME680", 0},
+		{"BME688", bme68x.VARIANT_GAS_HIGH},
+	} {
+		t.Run(variant.name, func(t *testing.T) {
+			var calibrations []bme68x.Calibration
+
+			for _, bus := range emulator.Buses {
+				t.Run(bus.Name, func(t *testing.T) {
+					e := emulator.New(emulator.WithVariant(variant.id), emulator.WithADC(adc))
+					d := bus.New(e, bme68x.WithHeatrDuration(10))
+
+					if err := d.Configure(); err != nil {
+						t.Fatalf("Configure: %v", err)
+					}
+
+					if d.VariantID != variant.id {
+						t.Errorf("variant ID 0x%02X, want 0x%02X", d.VariantID, variant.id)
+					}
+
+					m, err := d.Measure()
+					if err != nil {
+						t.Fatalf("Measure: %v", err)
+					}
+
+					if !m.NewData() || !m.GasValid() || !m.HeatStable() {
+						t.Errorf("status 0x%02X, want new data, valid gas and stable heater", m.Status)
+					}
+
+					want := compensate(d.Calibration(), bme68x.Raw{
+						Status:      bme68x.NEW_DATA_MSK | bme68x.GASM_VALID_MSK | bme68x.HEAT_STAB_MSK,
+						Temperature: adc.Temperature,
+						Pressure:    adc.Pressure,
+						Humidity:    adc.Humidity,
+						Gas:         adc.Gas,
+						GasRange:    adc.GasRange,
+					})
+
+					if m.Temperature != want.Temperature || m.Pressure != want.Pressure ||
+						m.Humidity != want.Humidity || m.GasResistance != want.GasResistance ||
+						m.TemperatureMilli != want.TemperatureMilli || m.PressurePa != want.PressurePa ||
+						m.HumidityMilli != want.HumidityMilli || m.GasResistanceOhms != want.GasResistanceOhms {
+						t.Errorf("measured %v, want %v", m, want)
+					}
+
+					calibrations = append(calibrations, d.Calibration())
+				})
+			}
+
+			if len(calibrations) == 2 && calibrations[0] != calibrations[1] {
+				t.Errorf("calibration over SPI %v, over I2C %v", calibrations[1], calibrations[0])
+			}
+		})
+	}
+}
+
+func TestSPIFullDuplex(t *testing.T) {
+	s := emulator.New().SPI()
+	cmd := bme68x.REG_CHIP_ID | bme68x.SPI_RD_MSK
+
+	// the byte read while the address is written is not a register
+	s.CS().Low()
+	r := make([]byte, 2)
+	err := s.Tx([]byte{cmd, 0}, r)
+	s.CS().High()
+
+	if err != nil {
+		t.Fatal(err)
+	} else if r[0] != 0xFF || r[1] != bme68x.CHIP_ID {
+		t.Errorf("read % X, want FF %02X", r, bme68x.CHIP_ID)
+	}
+
+	// the transaction goes on across Tx calls until the chip select is released
+	s.CS().Low()
+	err = s.Tx([]byte{cmd}, nil)
+	if err == nil {
+		err = s.Tx(nil, r[:1])
+	}
+	s.CS().High()
+
+	if err != nil {
+		t.Fatal(err)
+	} else if r[0] != bme68x.CHIP_ID {
+		t.Errorf("read %02X, want %02X", r[0], bme68x.CHIP_ID)
+	}
+
+	s.CS().Low()
+	defer s.CS().High()
+
+	if err := s.Tx([]byte{0}, make([]byte, 2)); err != emulator.ErrTxSize {
+		t.Errorf("Tx with different lengths returned %v, want %v", err, emulator.ErrTxSize)
+	}
+}
+
+func TestSPIChipSelect(t *testing.T) {
+	e := emulator.New()
+
+	if e.SPI() != e.SPI() {
+		t.Fatal("SPI returned different buses")
+	}
+
+	cs := e.SPI().CS()
+	if !cs.IsHigh() {
+		t.Fatal("chip select low at power-on")
+	}
+
+	if err := e.SPI().Tx([]byte{bme68x.REG_CHIP_ID | bme68x.SPI_RD_MSK}, nil); err != emulator.ErrNotSelected {
+		t.Errorf("Tx without chip select returned %v, want %v", err, emulator.ErrNotSelected)
+	}
+
+	// a pin which is never driven leaves the sensor deselected
+	if err := bme68x.NewSPI(e.SPI(), nopPin{}).Configure(); !errors.Is(err, emulator.ErrNotSelected) {
+		t.Errorf("Configure without chip select returned %v, want %v", err, emulator.ErrNotSelected)
+	}
+
+	// NewSPI drives the pin high, then each transaction toggles it twice
+	d := bme68x.NewSPI(e.SPI(), cs)
+	if err := d.Configure(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cs.Edges == 0 || cs.Edges%2 != 0 || !cs.IsHigh() {
+		t.Errorf("%d chip select edges, high %t, want an even number and high", cs.Edges, cs.IsHigh())
+	}
+}
+
+// nopPin is a chip select pin which is not connected.
+type nopPin struct{}
+
+func (nopPin) High() {}
+func (nopPin) Low()  {}
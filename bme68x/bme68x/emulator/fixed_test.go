@@ -0,0 +1,8 @@
+//go:build bme68x_fixed
+
+package emulator_test
+
+import "BME68x/bme68x"
+
+// compensate is the compensation used by the device.
+var compensate = bme68x.CompensateInt
@@ -0,0 +1,43 @@
+package emulator
+
+import (
+	"errors"
+)
+
+// ErrNoDevice is returned when no emulated sensor answers at the address.
+var ErrNoDevice = errors.New("emulator: no device at address")
+
+// I2C is an I2C bus connected to an emulated sensor. It implements
+// drivers.I2C.
+type I2C struct {
+	device *Device
+}
+
+// Tx performs an I2C transaction. The written data is either a register
+// address followed by a read, or register address and value pairs, as the
+// sensor does not auto-increment on writes.
+func (i *I2C) Tx(addr uint16, w, r []byte) error {
+	d := i.device
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if addr != d.address {
+		return ErrNoDevice
+	}
+
+	if len(w) == 0 {
+		return nil
+	}
+
+	if len(w) == 1 {
+		d.read(w[0], r)
+		return nil
+	}
+
+	for i := 0; i+1 < len(w); i += 2 {
+		d.write(w[i], w[i+1])
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package emulator
+
+import "time"
+
+type Option func(*Device)
+
+// WithAddress sets the I2C address of the emulated sensor.
+func WithAddress(addr uint16) Option {
+	return func(d *Device) {
+		d.address = addr
+	}
+}
+
+// WithVariant sets the variant ID, 0x00 for the BME680 and
+// bme68x.VARIANT_GAS_HIGH for the BME688.
+func WithVariant(variant uint8) Option {
+	return func(d *Device) {
+		d.variant = variant
+	}
+}
+
+// WithCalibration sets the calibration image, as read from REG_COEFF1,
+// REG_COEFF2 and REG_COEFF3.
+func WithCalibration(data [LenCalibration]byte) Option {
+	return func(d *Device) {
+		d.setCalibration(data)
+	}
+}
+
+// WithADC sets the raw values reported by the measurements.
+func WithADC(adc ADC) Option {
+	return func(d *Device) {
+		d.adc = adc
+	}
+}
+
+// WithIdac sets the heater current DAC reached by the heater control, 0x00
+// or 0xFF emulating an open or shorted heater.
+func WithIdac(idac uint8) Option {
+	return func(d *Device) {
+		d.idac = idac
+	}
+}
+
+// WithClock sets the function returning the current time, used to time the
+// measurements.
+func WithClock(now func() time.Time) Option {
+	return func(d *Device) {
+		d.now = now
+	}
+}
@@ -0,0 +1,182 @@
+package emulator
+
+import (
+	"errors"
+
+	"BME68x/bme68x"
+)
+
+var (
+	// ErrNotSelected is returned by SPI.Tx when the chip select pin is high.
+	ErrNotSelected = errors.New("emulator: chip select not asserted")
+	// ErrTxSize is returned by SPI.Tx when w and r have different lengths.
+	ErrTxSize = errors.New("emulator: w and r of different lengths")
+)
+
+// SPI is an SPI bus connected to an emulated sensor. It implements
+// drivers.SPI.
+//
+// The bus is full duplex: a byte is read into r for each byte written from
+// w. The first byte after the chip select is asserted is the register
+// address, with the read bit set to read registers from the next byte on, or
+// followed by values and register address and value pairs.
+type SPI struct {
+	device *Device
+	cs     Pin
+	// selects is the value of cs.selects at the last byte
+	selects int
+	// pos is the position of the next byte since the chip select
+	pos int
+	// addr is the address of the next register read or written
+	addr uint8
+	// reading is set when the first byte has the read bit set
+	reading bool
+}
+
+// Pin is the chip select pin of an SPI bus. It implements bme68x.Pin.
+type Pin struct {
+	high bool
+	// selects counts the falling edges, starting a new transaction
+	selects int
+	// Edges counts the level changes of the pin.
+	Edges int
+}
+
+// High deselects the sensor.
+func (p *Pin) High() {
+	if !p.high {
+		p.Edges++
+	}
+
+	p.high = true
+}
+
+// Low selects the sensor.
+func (p *Pin) Low() {
+	if p.high {
+		p.Edges++
+		p.selects++
+	}
+
+	p.high = false
+}
+
+// IsHigh reports whether the sensor is deselected.
+func (p *Pin) IsHigh() bool {
+	return p.high
+}
+
+// CS returns the chip select pin of the bus, high until driven.
+func (s *SPI) CS() *Pin {
+	return &s.cs
+}
+
+// Tx clocks len(w) bytes, or len(r) bytes when w is nil, with the chip select
+// asserted. Zeros are written when w is nil and the read bytes are dropped
+// when r is nil.
+func (s *SPI) Tx(w, r []byte) error {
+	d := s.device
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if s.cs.high {
+		return ErrNotSelected
+	}
+
+	if w != nil && r != nil && len(w) != len(r) {
+		return ErrTxSize
+	}
+
+	for i := range max(len(w), len(r)) {
+		var b byte
+		if w != nil {
+			b = w[i]
+		}
+
+		b = s.transfer(b)
+		if r != nil {
+			r[i] = b
+		}
+	}
+
+	return nil
+}
+
+// Transfer clocks a single byte with the chip select asserted.
+func (s *SPI) Transfer(b byte) (byte, error) {
+	d := s.device
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if s.cs.high {
+		return 0, ErrNotSelected
+	}
+
+	return s.transfer(b), nil
+}
+
+// transfer writes a byte and returns the byte read at the same time.
+func (s *SPI) transfer(b byte) byte {
+	d := s.device
+
+	// a falling edge of the chip select starts a new transaction
+	if s.cs.selects != s.selects {
+		s.selects = s.cs.selects
+		s.pos = 0
+	}
+
+	pos := s.pos
+	s.pos++
+
+	switch {
+	case pos == 0:
+		s.addr = b &^ bme68x.SPI_RD_MSK
+		s.reading = b&bme68x.SPI_RD_MSK != 0
+	case s.reading:
+		r := d.readSPI(s.addr)
+		s.addr++
+
+		return r
+	case pos%2 == 1:
+		d.writeSPI(s.addr, b)
+	default:
+		s.addr = b &^ bme68x.SPI_RD_MSK
+	}
+
+	// the sensor does not drive the data line while receiving
+	return 0xFF
+}
+
+// register maps a 7-bit SPI address to a register using the memory page.
+func (d *Device) register(addr uint8) uint8 {
+	addr &= bme68x.SPI_WR_MSK
+
+	if d.page&bme68x.MEM_PAGE_MSK == bme68x.MEM_PAGE0 {
+		return addr
+	}
+
+	return addr | bme68x.SPI_RD_MSK
+}
+
+func (d *Device) readSPI(addr uint8) byte {
+	// the status register is mapped in both pages
+	if addr&bme68x.SPI_WR_MSK == regStatus {
+		return d.page
+	}
+
+	var data [1]byte
+	d.read(d.register(addr), data[:])
+
+	return data[0]
+}
+
+func (d *Device) writeSPI(addr, value uint8) {
+	if addr == regStatus {
+		d.page = value & bme68x.MEM_PAGE_MSK
+		return
+	}
+
+	d.write(d.register(addr), value)
+}
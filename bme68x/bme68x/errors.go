@@ -0,0 +1,35 @@
+package bme68x
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotConnected is returned when the sensor does not answer on the bus.
+	ErrNotConnected = errors.New("device not found or not connected")
+	// ErrNoNewData is returned when no field buffer holds new data after the
+	// measurement period.
+	ErrNoNewData = errors.New("no new data")
+	// ErrHeaterUnstable is returned when the heater did not reach the target
+	// temperature during the gas measurement.
+	ErrHeaterUnstable = errors.New("heater not stable")
+	// ErrGasInvalid is returned when the gas measurement is not valid.
+	ErrGasInvalid = errors.New("gas measurement not valid")
+	// ErrInvalidConfig is returned when the configuration can not be applied.
+	ErrInvalidConfig = errors.New("invalid config")
+	// ErrInvalidCalibration is returned when an encoded calibration is
+	// corrupted or does not match the sensor.
+	ErrInvalidCalibration = errors.New("invalid calibration")
+)
+
+// ErrWrongChipID is returned when the chip ID read is not the one of a
+// BME680/BME688. Use errors.As to get the ID read.
+type ErrWrongChipID struct {
+	ChipID uint8
+}
+
+// Error implements error interface.
+func (e ErrWrongChipID) Error() string {
+	return fmt.Sprintf("wrong chip ID: 0x%02X, expected 0x%02X", e.ChipID, CHIP_ID)
+}
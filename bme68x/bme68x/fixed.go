@@ -0,0 +1,6 @@
+//go:build bme68x_fixed
+
+package bme68x
+
+// fixedPoint forces the integer compensation.
+const fixedPoint = true
@@ -0,0 +1,237 @@
+// Package gas tracks the baseline of the gas resistance measured by a
+// BME68x sensor. The gas resistance is only meaningful relative to the
+// baseline, the resistance in clean air, which settles once the sensor burnt
+// in.
+//
+// A Tracker goes through three states: warming while the heater and the
+// sensing layer settle, burning in while the baseline is learnt, then stable.
+// The baseline can be saved with Snapshot and restored after a reboot, which
+// skips the burn-in.
+//
+// Feed a Tracker with the measurements of a single heater temperature, use a
+// Tracker per step of a heater profile.
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"BME68x/bme68x"
+)
+
+const (
+	// WarmUp is the default warm-up duration.
+	WarmUp = 5 * time.Minute
+	// BurnIn is the default burn-in duration, after the warm-up.
+	BurnIn = 25 * time.Minute
+	// Window is the default time constant of the baseline once stable.
+	Window = 24 * time.Hour
+	// MaxAge is the default maximum age of a restored snapshot.
+	MaxAge = 7 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidTime is returned when a sample has no time or is older than
+	// the previous one.
+	ErrInvalidTime = errors.New("invalid gas sample time")
+	// ErrInvalidResistance is returned when a gas resistance is not a
+	// positive number.
+	ErrInvalidResistance = errors.New("invalid gas resistance")
+)
+
+// State is the state of a Tracker.
+type State uint8
+
+const (
+	// StateWarming is the warm-up, the samples are not used.
+	StateWarming State = iota
+	// StateBurnIn is the burn-in, the baseline is the mean of the samples.
+	StateBurnIn
+	// StateStable is the normal operation, the baseline slowly follows the
+	// samples.
+	StateStable
+)
+
+// String implements fmt.Stringer interface.
+func (s State) String() string {
+	switch s {
+	case StateWarming:
+		return "warming"
+	case StateBurnIn:
+		return "burn-in"
+	case StateStable:
+		return "stable"
+	}
+
+	return fmt.Sprintf("State(%d)", uint8(s))
+}
+
+// Tracker tracks the gas resistance baseline.
+type Tracker struct {
+	warmUp time.Duration
+	burnIn time.Duration
+	window time.Duration
+	maxAge time.Duration
+
+	state State
+	// start is the time of the first sample
+	start time.Time
+	// last is the time of the last sample used
+	last time.Time
+	// baseline is the baseline resistance in Ohms
+	baseline float32
+	// samples is the number of samples in the baseline
+	samples uint32
+	// restored is set when the baseline comes from a snapshot
+	restored bool
+	// snapshot is the time of the restored snapshot, until its age is
+	// checked at the next sample
+	snapshot time.Time
+}
+
+// New returns a Tracker waiting for its first sample.
+func New(opts ...Option) *Tracker {
+	t := &Tracker{
+		warmUp: WarmUp,
+		burnIn: BurnIn,
+		window: Window,
+		maxAge: MaxAge,
+	}
+
+	for _, option := range opts {
+		option(t)
+	}
+
+	return t
+}
+
+// Update adds a measurement, at the time of the measurement, using the
+// integer gas resistance set by both compensations. It returns
+// bme68x.ErrNoNewData, bme68x.ErrGasInvalid or bme68x.ErrHeaterUnstable
+// when the measurement is rejected.
+func (t *Tracker) Update(m bme68x.Measurement) error {
+	if !m.NewData() {
+		return bme68x.ErrNoNewData
+	}
+
+	if err := m.GasErr(); err != nil {
+		return err
+	}
+
+	return t.Add(m.Time, float32(m.GasResistanceOhms))
+}
+
+// Add adds a gas resistance in Ohms measured at the time. The resistance
+// must be positive, and the time not older than the previous sample.
+func (t *Tracker) Add(at time.Time, resistance float32) error {
+	if !(resistance > 0) || math.IsInf(float64(resistance), 0) {
+		return fmt.Errorf("%w: %v", ErrInvalidResistance, resistance)
+	}
+
+	if at.IsZero() {
+		return fmt.Errorf("%w: zero time", ErrInvalidTime)
+	}
+
+	// the warm-up samples only set the start
+	if at.Before(t.last) || at.Before(t.start) {
+		return fmt.Errorf("%w: %v before the previous sample", ErrInvalidTime, at)
+	}
+
+	if !t.snapshot.IsZero() {
+		t.expire(at)
+	}
+
+	if t.start.IsZero() {
+		t.start = at
+	}
+
+	elapsed := at.Sub(t.start)
+
+	switch {
+	case t.state == StateWarming && elapsed < t.warmUp:
+		return nil
+	case t.state == StateWarming && t.restored:
+		t.state = StateStable
+	case t.state == StateWarming:
+		t.state = StateBurnIn
+	case t.state == StateBurnIn && elapsed >= t.warmUp+t.burnIn && t.samples > 0:
+		t.state = StateStable
+	}
+
+	switch t.state {
+	case StateBurnIn:
+		t.samples++
+		t.baseline += (resistance - t.baseline) / float32(t.samples)
+	case StateStable:
+		// exponential moving average with a time constant of window, from
+		// the next sample after a restore
+		if !t.last.IsZero() {
+			alpha := 1 - math.Exp(-float64(at.Sub(t.last))/float64(t.window))
+			t.baseline += float32(alpha) * (resistance - t.baseline)
+		}
+
+		t.samples++
+	}
+
+	t.last = at
+
+	return nil
+}
+
+// State returns the state of the tracker.
+func (t *Tracker) State() State {
+	return t.state
+}
+
+// Ready reports whether the baseline is stable.
+func (t *Tracker) Ready() bool {
+	return t.state == StateStable
+}
+
+// Baseline returns the baseline resistance in Ohms, 0 until the burn-in
+// starts. It is only an estimate until the tracker is ready.
+func (t *Tracker) Baseline() float32 {
+	return t.baseline
+}
+
+// Ratio returns the gas resistance relative to the baseline, 1 in clean air
+// and lower with pollutants. It is 0 without baseline.
+func (t *Tracker) Ratio(resistance float32) float32 {
+	if t.baseline == 0 {
+		return 0
+	}
+
+	return resistance / t.baseline
+}
+
+// TimeToReady returns the time left until the baseline is stable, from the
+// first sample or now without sample.
+func (t *Tracker) TimeToReady(now time.Time) time.Duration {
+	if t.state == StateStable {
+		return 0
+	}
+
+	total := t.warmUp
+	if !t.restored {
+		total += t.burnIn
+	}
+
+	if t.start.IsZero() {
+		return total
+	}
+
+	return max(t.start.Add(total).Sub(now), 0)
+}
+
+// Reset forgets the samples and the baseline, as after a power cycle of the
+// sensor without snapshot.
+func (t *Tracker) Reset() {
+	*t = Tracker{
+		warmUp: t.warmUp,
+		burnIn: t.burnIn,
+		window: t.window,
+		maxAge: t.maxAge,
+	}
+}
@@ -0,0 +1,250 @@
+package gas
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"BME68x/bme68x"
+)
+
+var epoch = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// feed adds a constant resistance every minute from the time from to the
+// time to.
+func feed(t *testing.T, tr *Tracker, from, to time.Duration, resistance float32) {
+	t.Helper()
+
+	for d := from; d <= to; d += time.Minute {
+		if err := tr.Add(epoch.Add(d), resistance); err != nil {
+			t.Fatalf("Add at %v: %v", d, err)
+		}
+	}
+}
+
+func TestTrackerStates(t *testing.T) {
+	tr := New(WithWarmUp(5*time.Minute), WithBurnIn(10*time.Minute))
+
+	feed(t, tr, 0, 4*time.Minute, 1e3)
+	if tr.State() != StateWarming || tr.Baseline() != 0 {
+		t.Fatalf("state %v, baseline %v after the warm-up, want %v, 0", tr.State(), tr.Baseline(), StateWarming)
+	}
+
+	feed(t, tr, 5*time.Minute, 14*time.Minute, 50e3)
+	if tr.State() != StateBurnIn || tr.Baseline() != 50e3 {
+		t.Fatalf("state %v, baseline %v during the burn-in, want %v, 50000", tr.State(), tr.Baseline(), StateBurnIn)
+	}
+
+	feed(t, tr, 15*time.Minute, 15*time.Minute, 50e3)
+	if !tr.Ready() {
+		t.Fatalf("state %v after the burn-in, want %v", tr.State(), StateStable)
+	}
+}
+
+func TestTrackerAddInvalidTime(t *testing.T) {
+	tr := New(WithWarmUp(5*time.Minute), WithBurnIn(10*time.Minute))
+
+	if err := tr.Add(time.Time{}, 1e3); !errors.Is(err, ErrInvalidTime) {
+		t.Errorf("Add of the zero time returned %v, want %v", err, ErrInvalidTime)
+	}
+
+	// during the warm-up, then once the samples are used
+	for _, at := range []time.Duration{time.Minute, 20 * time.Minute} {
+		feed(t, tr, at, at, 1e3)
+
+		if err := tr.Add(epoch.Add(at-time.Second), 1e3); !errors.Is(err, ErrInvalidTime) {
+			t.Errorf("Add of a time before %v returned %v, want %v", at, err, ErrInvalidTime)
+		}
+	}
+
+	feed(t, tr, 21*time.Minute, 21*time.Minute, 1e3)
+
+	if !tr.Ready() || tr.Baseline() != 1e3 {
+		t.Errorf("state %v, baseline %v, want %v, 1000", tr.State(), tr.Baseline(), StateStable)
+	}
+}
+
+func TestTrackerRestore(t *testing.T) {
+	snapshot := Snapshot{Baseline: 80e3, Samples: 100, Time: epoch.Add(-2 * time.Hour)}
+
+	for _, c := range []struct {
+		name     string
+		opts     []Option
+		snapshot Snapshot
+		baseline float32
+		ready    bool
+	}{
+		{"recent", nil, snapshot, 80e3, true},
+		// burns in again on the new samples
+		{"expired", []Option{WithMaxAge(time.Hour)}, snapshot, 40e3, false},
+		{"any age", []Option{WithMaxAge(0)}, Snapshot{Baseline: 80e3, Time: epoch.AddDate(-1, 0, 0)}, 80e3, true},
+		// the clock restarted without real-time clock
+		{"future", []Option{WithMaxAge(time.Hour)}, Snapshot{Baseline: 80e3, Time: epoch.AddDate(1, 0, 0)}, 80e3, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tr := New(append([]Option{WithWarmUp(5 * time.Minute)}, c.opts...)...)
+			if err := tr.Restore(c.snapshot); err != nil {
+				t.Fatal(err)
+			}
+
+			feed(t, tr, 0, 5*time.Minute, 40e3)
+
+			if got := tr.Baseline(); got != c.baseline {
+				t.Errorf("baseline %v after the warm-up, want %v", got, c.baseline)
+			}
+
+			if got := tr.Ready(); got != c.ready {
+				t.Errorf("ready %v after the warm-up, want %v", got, c.ready)
+			}
+		})
+	}
+}
+
+func TestTrackerRestoreInvalid(t *testing.T) {
+	for _, s := range []Snapshot{
+		{Baseline: 0, Time: epoch},
+		{Baseline: -1, Time: epoch},
+		{Baseline: 80e3},
+	} {
+		if err := New().Restore(s); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("Restore(%+v) returned %v, want %v", s, err, ErrInvalidSnapshot)
+		}
+	}
+}
+
+func TestTrackerUpdate(t *testing.T) {
+	const valid = bme68x.NEW_DATA_MSK | bme68x.GASM_VALID_MSK | bme68x.HEAT_STAB_MSK
+
+	for _, c := range []struct {
+		name   string
+		status uint8
+		want   error
+	}{
+		{"no new data", valid &^ bme68x.NEW_DATA_MSK, bme68x.ErrNoNewData},
+		{"gas invalid", valid &^ bme68x.GASM_VALID_MSK, bme68x.ErrGasInvalid},
+		{"heater unstable", valid &^ bme68x.HEAT_STAB_MSK, bme68x.ErrHeaterUnstable},
+		{"valid", valid, nil},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			tr := New(WithWarmUp(0))
+
+			err := tr.Update(bme68x.Measurement{Time: epoch, Status: c.status, GasResistanceOhms: 50e3})
+			if !errors.Is(err, c.want) {
+				t.Fatalf("Update returned %v, want %v", err, c.want)
+			}
+
+			// a rejected sample does not start the tracker
+			want := BurnIn
+			if c.want == nil {
+				want -= time.Minute
+			}
+
+			if got := tr.TimeToReady(epoch.Add(time.Minute)); got != want {
+				t.Errorf("time to ready %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTrackerAddInvalidResistance(t *testing.T) {
+	tr := New()
+
+	for _, r := range []float32{0, -1, float32(math.NaN()), float32(math.Inf(1))} {
+		if err := tr.Add(epoch, r); !errors.Is(err, ErrInvalidResistance) {
+			t.Errorf("Add of %v returned %v, want %v", r, err, ErrInvalidResistance)
+		}
+	}
+}
+
+func TestTrackerTimeToReady(t *testing.T) {
+	tr := New(WithWarmUp(5*time.Minute), WithBurnIn(10*time.Minute))
+
+	// from now before the first sample
+	if got := tr.TimeToReady(epoch.Add(time.Hour)); got != 15*time.Minute {
+		t.Errorf("time to ready %v before the first sample, want 15m", got)
+	}
+
+	feed(t, tr, 0, 8*time.Minute, 50e3)
+	if got := tr.TimeToReady(epoch.Add(8 * time.Minute)); got != 7*time.Minute {
+		t.Errorf("time to ready %v during the burn-in, want 7m", got)
+	}
+
+	feed(t, tr, 9*time.Minute, 15*time.Minute, 50e3)
+	if got := tr.TimeToReady(epoch.Add(15 * time.Minute)); got != 0 {
+		t.Errorf("time to ready %v once stable, want 0", got)
+	}
+
+	// only the warm-up after a restore
+	tr = New(WithWarmUp(5*time.Minute), WithBurnIn(10*time.Minute))
+	if err := tr.Restore(Snapshot{Baseline: 50e3, Time: epoch}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tr.TimeToReady(epoch); got != 5*time.Minute {
+		t.Errorf("time to ready %v after a restore, want 5m", got)
+	}
+
+	feed(t, tr, time.Minute, 3*time.Minute, 50e3)
+	if got := tr.TimeToReady(epoch.Add(3 * time.Minute)); got != 3*time.Minute {
+		t.Errorf("time to ready %v during the warm-up after a restore, want 3m", got)
+	}
+}
+
+func TestSnapshotBinary(t *testing.T) {
+	s := Snapshot{Baseline: 123456.5, Samples: 4242, Time: epoch}
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Snapshot
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Baseline != s.Baseline || got.Samples != s.Samples || !got.Time.Equal(s.Time) {
+		t.Errorf("snapshot %+v, want %+v", got, s)
+	}
+
+	for _, c := range []struct {
+		name   string
+		modify func(data []byte) []byte
+	}{
+		{"flipped baseline", func(data []byte) []byte { data[2] ^= 0xFF; return data }},
+		{"flipped CRC", func(data []byte) []byte { data[len(data)-1] ^= 0x01; return data }},
+		{"short", func(data []byte) []byte { return data[:len(data)-1] }},
+		{"version", func(data []byte) []byte { data[0]++; return data }},
+	} {
+		data := c.modify(append([]byte(nil), data...))
+		if err := got.UnmarshalBinary(data); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("UnmarshalBinary of a %s snapshot returned %v, want %v", c.name, err, ErrInvalidSnapshot)
+		}
+	}
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	tr := New(WithWarmUp(time.Minute), WithBurnIn(time.Minute))
+	feed(t, tr, 0, 3*time.Minute, 50e3)
+
+	data, err := json.Marshal(tr.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := New(WithWarmUp(time.Minute))
+	if err := restored.Restore(s); err != nil {
+		t.Fatalf("Restore of %s: %v", data, err)
+	}
+
+	if restored.Baseline() != tr.Baseline() || !s.Time.Equal(epoch.Add(3*time.Minute)) {
+		t.Errorf("snapshot %s restored with baseline %v, want %v", data, restored.Baseline(), tr.Baseline())
+	}
+}
@@ -0,0 +1,36 @@
+package gas
+
+import "time"
+
+type Option func(*Tracker)
+
+// WithWarmUp sets the warm-up duration, during which the samples are not
+// used.
+func WithWarmUp(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.warmUp = d
+	}
+}
+
+// WithBurnIn sets the burn-in duration after the warm-up, during which the
+// baseline is learnt.
+func WithBurnIn(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.burnIn = d
+	}
+}
+
+// WithWindow sets the time constant of the baseline once stable.
+func WithWindow(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.window = d
+	}
+}
+
+// WithMaxAge sets the maximum age of a restored snapshot, 0 to accept any
+// age.
+func WithMaxAge(d time.Duration) Option {
+	return func(t *Tracker) {
+		t.maxAge = d
+	}
+}
@@ -0,0 +1,134 @@
+package gas
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"time"
+)
+
+const (
+	// SnapshotSize is the size of the binary encoding of a Snapshot: the
+	// format version, the baseline, the sample count and the time in little
+	// endian, and a CRC-32 (IEEE) of the previous bytes.
+	SnapshotSize = 21
+	// SnapshotVersion is the version of the binary encoding.
+	SnapshotVersion uint8 = 1
+)
+
+// ErrInvalidSnapshot is returned when a snapshot is corrupted or holds no
+// baseline.
+var ErrInvalidSnapshot = errors.New("invalid gas baseline snapshot")
+
+// Snapshot is the baseline of a Tracker, to be persisted and restored after a
+// reboot.
+type Snapshot struct {
+	// Baseline is the baseline resistance in Ohms.
+	Baseline float32 `json:"baseline"`
+	// Samples is the number of samples in the baseline.
+	Samples uint32 `json:"samples"`
+	// Time is the time of the last sample.
+	Time time.Time `json:"time"`
+}
+
+// Snapshot returns the baseline, valid once the tracker is ready.
+func (t *Tracker) Snapshot() Snapshot {
+	return Snapshot{
+		Baseline: t.baseline,
+		Samples:  t.samples,
+		Time:     t.last,
+	}
+}
+
+// Restore sets the baseline saved by Snapshot. The tracker becomes stable
+// after the warm-up, skipping the burn-in.
+//
+// The sensor drifts while it is not running, so a snapshot older than the
+// maximum age at the next sample is dropped and the tracker reset. Its age
+// can not be known when the snapshot is more recent than the sample, such as
+// on a board without real-time clock, and it is then kept.
+func (t *Tracker) Restore(s Snapshot) error {
+	if !(s.Baseline > 0) || math.IsInf(float64(s.Baseline), 0) {
+		return fmt.Errorf("%w: baseline %v", ErrInvalidSnapshot, s.Baseline)
+	}
+
+	if s.Time.IsZero() && t.maxAge > 0 {
+		return fmt.Errorf("%w: no time", ErrInvalidSnapshot)
+	}
+
+	t.baseline = s.Baseline
+	t.samples = s.Samples
+	t.restored = true
+	t.snapshot = s.Time
+
+	if t.state == StateBurnIn {
+		t.state = StateStable
+	}
+
+	return nil
+}
+
+// expire resets the tracker when the restored snapshot is older than the
+// maximum age at the time of the sample.
+func (t *Tracker) expire(at time.Time) {
+	age := at.Sub(t.snapshot)
+	t.snapshot = time.Time{}
+
+	if t.maxAge > 0 && age > t.maxAge {
+		t.Reset()
+	}
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler interface.
+func (s Snapshot) MarshalBinary() ([]byte, error) {
+	le := binary.LittleEndian
+
+	data := make([]byte, 0, SnapshotSize)
+	data = append(data, SnapshotVersion)
+	data = le.AppendUint32(data, math.Float32bits(s.Baseline))
+	data = le.AppendUint32(data, s.Samples)
+	data = le.AppendUint64(data, uint64(s.unix()))
+
+	return le.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler interface. It checks
+// the version and the CRC.
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	if len(data) != SnapshotSize {
+		return fmt.Errorf("%w: size %d, expected %d", ErrInvalidSnapshot, len(data), SnapshotSize)
+	}
+
+	if data[0] != SnapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, data[0])
+	}
+
+	le := binary.LittleEndian
+
+	crc := le.Uint32(data[SnapshotSize-4:])
+	if sum := crc32.ChecksumIEEE(data[:SnapshotSize-4]); sum != crc {
+		return fmt.Errorf("%w: CRC 0x%08X, expected 0x%08X", ErrInvalidSnapshot, crc, sum)
+	}
+
+	*s = Snapshot{
+		Baseline: math.Float32frombits(le.Uint32(data[1:])),
+		Samples:  le.Uint32(data[5:]),
+	}
+
+	if sec := int64(le.Uint64(data[9:])); sec != 0 {
+		s.Time = time.Unix(sec, 0)
+	}
+
+	return nil
+}
+
+// unix returns the time in seconds, 0 for the zero time.
+func (s Snapshot) unix() int64 {
+	if s.Time.IsZero() {
+		return 0
+	}
+
+	return s.Time.Unix()
+}
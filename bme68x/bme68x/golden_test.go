@@ -0,0 +1,173 @@
+package bme68x
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+)
+
+// golden holds the vectors of testdata/golden.json, generated by
+// testdata/golden.c from the Bosch SensorAPI formulas.
+type golden struct {
+	Compensation []goldenCompensation `json:"compensation"`
+	ResHeat      []goldenResHeat      `json:"res_heat"`
+	GasWait      []goldenGasWait      `json:"gas_wait"`
+}
+
+type goldenCompensation struct {
+	Calibration string `json:"calibration"`
+
+	AdcTemp  uint32 `json:"adc_temp"`
+	AdcPres  uint32 `json:"adc_pres"`
+	AdcHum   uint16 `json:"adc_hum"`
+	AdcGas   uint16 `json:"adc_gas"`
+	GasRange uint8  `json:"gas_range"`
+
+	TFine       float32 `json:"t_fine"`
+	Temperature float32 `json:"temperature"`
+	Pressure    float32 `json:"pressure"`
+	Humidity    float32 `json:"humidity"`
+	GasLow      float32 `json:"gas_low"`
+	GasHigh     float32 `json:"gas_high"`
+
+	TFineInt       int32  `json:"t_fine_int"`
+	TemperatureInt int16  `json:"temperature_int"`
+	PressureInt    uint32 `json:"pressure_int"`
+	HumidityInt    uint32 `json:"humidity_int"`
+	GasLowInt      uint32 `json:"gas_low_int"`
+	GasHighInt     uint32 `json:"gas_high_int"`
+}
+
+type goldenResHeat struct {
+	Calibration string `json:"calibration"`
+	Ambient     int8   `json:"ambient"`
+	Target      uint16 `json:"target"`
+	ResHeat     uint8  `json:"res_heat"`
+	ResHeatInt  uint8  `json:"res_heat_int"`
+}
+
+type goldenGasWait struct {
+	Dur     uint16 `json:"dur"`
+	GasWait uint8  `json:"gas_wait"`
+}
+
+func loadGolden(t *testing.T) golden {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/golden.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var g golden
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+// goldenCalibration parses a calibration image, the REG_COEFF1, REG_COEFF2
+// and REG_COEFF3 blocks in hexadecimal.
+func goldenCalibration(t *testing.T, image string) Calibration {
+	t.Helper()
+
+	var data [LEN_COEFF_ALL]byte
+	if n, err := hex.Decode(data[:], []byte(image)); err != nil || n != len(data) {
+		t.Fatalf("invalid calibration image %q: %d bytes, %v", image, n, err)
+	}
+
+	return parseCalibration(data)
+}
+
+// near reports whether got is within the relative tolerance of want.
+func near(got, want float32, tolerance float64) bool {
+	return math.Abs(float64(got)-float64(want)) <= tolerance*math.Max(1, math.Abs(float64(want)))
+}
+
+func TestGoldenCompensation(t *testing.T) {
+	for i, v := range loadGolden(t).Compensation {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			c := goldenCalibration(t, v.Calibration)
+
+			temp, tFine := c.calcTemperature(v.AdcTemp)
+			if !near(tFine, v.TFine, 1e-6) || !near(temp, v.Temperature, 1e-5) {
+				t.Errorf("calcTemperature(%d) = %v, %v, want %v, %v", v.AdcTemp, temp, tFine, v.Temperature, v.TFine)
+			}
+
+			if got := c.calcPressure(v.AdcPres, v.TFine); !near(got, v.Pressure, 1e-5) {
+				t.Errorf("calcPressure(%d) = %v, want %v", v.AdcPres, got, v.Pressure)
+			}
+
+			if got := c.calcHumidity(v.AdcHum, v.TFine); !near(got, v.Humidity, 1e-4) {
+				t.Errorf("calcHumidity(%d) = %v, want %v", v.AdcHum, got, v.Humidity)
+			}
+
+			if got := c.calcGasResistanceLow(v.AdcGas, v.GasRange); !near(got, v.GasLow, 1e-5) {
+				t.Errorf("calcGasResistanceLow(%d, %d) = %v, want %v", v.AdcGas, v.GasRange, got, v.GasLow)
+			}
+
+			if got := c.calcGasResistanceHigh(v.AdcGas, v.GasRange); !near(got, v.GasHigh, 1e-5) {
+				t.Errorf("calcGasResistanceHigh(%d, %d) = %v, want %v", v.AdcGas, v.GasRange, got, v.GasHigh)
+			}
+		})
+	}
+}
+
+func TestGoldenCompensationInt(t *testing.T) {
+	for i, v := range loadGolden(t).Compensation {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			c := goldenCalibration(t, v.Calibration)
+
+			if temp, tFine := c.calcTemperatureInt(v.AdcTemp); temp != v.TemperatureInt || tFine != v.TFineInt {
+				t.Errorf("calcTemperatureInt(%d) = %d, %d, want %d, %d", v.AdcTemp, temp, tFine, v.TemperatureInt, v.TFineInt)
+			}
+
+			if got := c.calcPressureInt(v.AdcPres, v.TFineInt); got != v.PressureInt {
+				t.Errorf("calcPressureInt(%d) = %d, want %d", v.AdcPres, got, v.PressureInt)
+			}
+
+			if got := c.calcHumidityInt(v.AdcHum, v.TFineInt); got != v.HumidityInt {
+				t.Errorf("calcHumidityInt(%d) = %d, want %d", v.AdcHum, got, v.HumidityInt)
+			}
+
+			if got := c.calcGasResistanceLowInt(v.AdcGas, v.GasRange); got != v.GasLowInt {
+				t.Errorf("calcGasResistanceLowInt(%d, %d) = %d, want %d", v.AdcGas, v.GasRange, got, v.GasLowInt)
+			}
+
+			if got := c.calcGasResistanceHighInt(v.AdcGas, v.GasRange); got != v.GasHighInt {
+				t.Errorf("calcGasResistanceHighInt(%d, %d) = %d, want %d", v.AdcGas, v.GasRange, got, v.GasHighInt)
+			}
+		})
+	}
+}
+
+func TestGoldenResHeat(t *testing.T) {
+	for i, v := range loadGolden(t).ResHeat {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			d := &Device{
+				calibration: goldenCalibration(t, v.Calibration),
+				config:      &Config{AmbientTemperature: v.Ambient},
+			}
+
+			if got := d.calcResistanceHeat(v.Target); got != v.ResHeat {
+				t.Errorf("calcResistanceHeat(%d) at %d°C = %d, want %d", v.Target, v.Ambient, got, v.ResHeat)
+			}
+
+			if got := d.calcResistanceHeatInt(v.Target); got != v.ResHeatInt {
+				t.Errorf("calcResistanceHeatInt(%d) at %d°C = %d, want %d", v.Target, v.Ambient, got, v.ResHeatInt)
+			}
+		})
+	}
+}
+
+func TestGoldenGasWait(t *testing.T) {
+	for _, v := range loadGolden(t).GasWait {
+		if got := new(nil).calcGasWait(v.Dur); got != v.GasWait {
+			t.Errorf("calcGasWait(%d) = 0x%02X, want 0x%02X", v.Dur, got, v.GasWait)
+		}
+	}
+}
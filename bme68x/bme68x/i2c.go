@@ -1,6 +1,7 @@
 package bme68x
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -9,37 +10,51 @@ import (
 
 type i2c struct {
 	bus drivers.I2C
+	// buf holds the register address of a read or the interleaved
+	// register/value pairs of a write
+	buf [LEN_INTERLEAVE_BUFF]byte
 }
 
-// Reset performs a soft reset of the BME68x sensor.
-func (i *i2c) Reset(addr uint16) error {
-	if err := i.Write(addr, []uint8{REG_SOFT_RESET}, []byte{CMD_RESET}); err != nil {
+// Reset performs a soft reset of the BME68x sensor, the reset period being
+// cut short when the context is done.
+func (i *i2c) Reset(ctx context.Context, addr uint16) error {
+	i.buf[0] = REG_SOFT_RESET
+	i.buf[1] = CMD_RESET
+
+	if err := i.bus.Tx(addr, i.buf[:2], nil); err != nil {
 		return fmt.Errorf("failed to soft reset command: %w", err)
 	}
 
-	time.Sleep(time.Duration(PeriodReset) * time.Microsecond)
-
-	return nil
+	return sleep(ctx, time.Duration(PeriodReset)*time.Microsecond)
 }
 
 // Read reads data from the BME68x sensor over I2C.
 func (i *i2c) Read(addr uint16, reg uint8, data []byte) error {
-	return i.bus.Tx(addr, []byte{reg}, data)
+	i.buf[0] = reg
+
+	return i.bus.Tx(addr, i.buf[:1], data)
 }
 
-// Write writes data to the BME68x sensor over I2C.
+// Write writes data to the BME68x sensor over I2C. The register/value pairs
+// are interleaved in burst writes of at most LEN_INTERLEAVE_BUFF bytes.
 func (i *i2c) Write(addr uint16, reg []uint8, data []byte) error {
-	buf := make([]uint8, LEN_INTERLEAVE_BUFF)
+	if len(reg) != len(data) {
+		return fmt.Errorf("%d registers for %d values", len(reg), len(data))
+	}
+
+	for len(data) > 0 {
+		n := min(len(data), int(LEN_INTERLEAVE_BUFF/2))
 
-	if len(data) > 0 && len(data) <= int(LEN_INTERLEAVE_BUFF/2) {
-		for i := 0; i < len(data); i++ {
-			buf[2*i] = reg[i]
-			buf[2*i+1] = data[i]
+		for j := 0; j < n; j++ {
+			i.buf[2*j] = reg[j]
+			i.buf[2*j+1] = data[j]
 		}
 
-		if err := i.bus.Tx(uint16(addr), buf, nil); err != nil {
+		if err := i.bus.Tx(addr, i.buf[:2*n], nil); err != nil {
 			return err
 		}
+
+		reg, data = reg[n:], data[n:]
 	}
 
 	return nil
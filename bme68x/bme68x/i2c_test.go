@@ -0,0 +1,73 @@
+package bme68x
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// recordingI2C records the written bytes of each transaction.
+type recordingI2C struct {
+	writes [][]byte
+}
+
+func (b *recordingI2C) Tx(addr uint16, w, r []byte) error {
+	if addr != Address {
+		return fmt.Errorf("address 0x%02X, want 0x%02X", addr, Address)
+	}
+
+	b.writes = append(b.writes, append([]byte(nil), w...))
+
+	return nil
+}
+
+func TestI2CWrite(t *testing.T) {
+	const pairs = int(LEN_INTERLEAVE_BUFF / 2)
+
+	for _, n := range []int{1, 2, pairs - 1, pairs, pairs + 1, 2*pairs + 3} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			reg, data := make([]uint8, n), make([]byte, n)
+			for i := range n {
+				reg[i], data[i] = REG_RES_HEAT0+uint8(i), byte(0xA0+i)
+			}
+
+			bus := &recordingI2C{}
+			if err := (&i2c{bus: bus}).Write(Address, reg, data); err != nil {
+				t.Fatal(err)
+			}
+
+			// exactly the pairs, in bursts of at most LEN_INTERLEAVE_BUFF bytes
+			var want [][]byte
+			for i := 0; i < n; i += pairs {
+				var burst []byte
+				for j := i; j < min(i+pairs, n); j++ {
+					burst = append(burst, reg[j], data[j])
+				}
+
+				want = append(want, burst)
+			}
+
+			if len(bus.writes) != len(want) {
+				t.Fatalf("%d transactions % X, want %d", len(bus.writes), bus.writes, len(want))
+			}
+
+			for i := range want {
+				if !bytes.Equal(bus.writes[i], want[i]) {
+					t.Errorf("transaction %d wrote % X, want % X", i, bus.writes[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestI2CWriteMismatch(t *testing.T) {
+	bus := &recordingI2C{}
+
+	if err := (&i2c{bus: bus}).Write(Address, []uint8{REG_CTRL_HUM, REG_CTRL_MEAS}, []byte{1}); err == nil {
+		t.Error("Write of 2 registers with 1 value succeeded")
+	}
+
+	if len(bus.writes) != 0 {
+		t.Errorf("Write of mismatched lengths sent % X", bus.writes)
+	}
+}
@@ -0,0 +1,243 @@
+// Package iaq estimates an indoor air quality index from the humidity and the
+// gas resistance measured by a BME68x sensor.
+//
+// This is an open estimator, NOT the Bosch BSEC IAQ: the algorithm is
+// documented below, it is not calibrated against reference instruments, and
+// its index, equivalent CO2 and breath VOC values are not comparable with
+// the ones of BSEC.
+//
+// The air quality score is the sum of a humidity score, up to 25 points when
+// the relative humidity is at 40%, and of a gas score, up to 75 points when
+// the gas resistance is at or above its baseline in clean air and
+// proportional to it below. The index maps the score from 0 (best) to 500
+// (worst), as the IAQ scale of the BME680 datasheet.
+package iaq
+
+import (
+	"fmt"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/gas"
+)
+
+const (
+	// HumidityBaseline is the default optimal relative humidity in percent.
+	HumidityBaseline = 40
+	// HumidityWeight is the default weight of the humidity in the score, the
+	// gas having the rest.
+	HumidityWeight = 0.25
+	// HighAccuracySamples is the number of samples in the gas baseline from
+	// which the accuracy is high.
+	HighAccuracySamples = 1000
+)
+
+// Accuracy is the accuracy of an estimate, following the gas baseline.
+type Accuracy uint8
+
+const (
+	// AccuracyUnreliable is reported during the warm-up, without baseline.
+	AccuracyUnreliable Accuracy = iota
+	// AccuracyLow is reported during the burn-in.
+	AccuracyLow
+	// AccuracyMedium is reported once the baseline is stable.
+	AccuracyMedium
+	// AccuracyHigh is reported once the baseline holds HighAccuracySamples.
+	AccuracyHigh
+)
+
+// String implements fmt.Stringer interface.
+func (a Accuracy) String() string {
+	switch a {
+	case AccuracyUnreliable:
+		return "unreliable"
+	case AccuracyLow:
+		return "low"
+	case AccuracyMedium:
+		return "medium"
+	case AccuracyHigh:
+		return "high"
+	}
+
+	return fmt.Sprintf("Accuracy(%d)", uint8(a))
+}
+
+// Estimate is an air quality estimate.
+type Estimate struct {
+	// IAQ is the index, from 0 (best) to 500 (worst).
+	IAQ float32
+	// Accuracy is the accuracy of the estimate.
+	Accuracy Accuracy
+	// ECO2 is an approximation of the equivalent CO2 in ppm.
+	ECO2 float32
+	// BVOC is an approximation of the breath VOC in ppm.
+	BVOC float32
+}
+
+// Quality returns the air quality class of the index, as in the BME680
+// datasheet.
+func (e Estimate) Quality() string {
+	switch {
+	case e.IAQ <= 50:
+		return "excellent"
+	case e.IAQ <= 100:
+		return "good"
+	case e.IAQ <= 150:
+		return "lightly polluted"
+	case e.IAQ <= 200:
+		return "moderately polluted"
+	case e.IAQ <= 250:
+		return "heavily polluted"
+	case e.IAQ <= 350:
+		return "severely polluted"
+	}
+
+	return "extremely polluted"
+}
+
+// String implements fmt.Stringer interface.
+func (e Estimate) String() string {
+	return fmt.Sprintf("IAQ (non-BSEC): %.0f (%s, accuracy %s), eCO2: %.0fppm, bVOC: %.2fppm",
+		e.IAQ, e.Quality(), e.Accuracy, e.ECO2, e.BVOC)
+}
+
+// Estimator estimates the air quality from measurements, learning the gas
+// baseline with a gas.Tracker.
+type Estimator struct {
+	tracker          *gas.Tracker
+	humidityBaseline float32
+	humidityWeight   float32
+}
+
+// New returns an Estimator learning the gas baseline with the tracker, which
+// may have been restored from a snapshot.
+func New(tracker *gas.Tracker, opts ...Option) *Estimator {
+	e := &Estimator{
+		tracker:          tracker,
+		humidityBaseline: HumidityBaseline,
+		humidityWeight:   HumidityWeight,
+	}
+
+	for _, option := range opts {
+		option(e)
+	}
+
+	return e
+}
+
+// Update adds the measurement to the gas baseline and returns the estimate.
+// It returns the errors of gas.Tracker.Update when the measurement is
+// rejected. Without baseline the estimate relies on the humidity only. The
+// integer fields of the measurement are used, set by both compensations.
+func (e *Estimator) Update(m bme68x.Measurement) (Estimate, error) {
+	if err := e.tracker.Update(m); err != nil {
+		return Estimate{}, err
+	}
+
+	// without baseline, the humidity weights the whole score
+	ratio, weight := float32(0), float32(1)
+	if e.tracker.Baseline() > 0 {
+		ratio, weight = e.tracker.Ratio(float32(m.GasResistanceOhms)), e.humidityWeight
+	}
+
+	iaq := Index(float32(m.HumidityMilli)/1000, ratio, e.humidityBaseline, weight)
+
+	return Estimate{
+		IAQ:      iaq,
+		Accuracy: e.accuracy(),
+		ECO2:     ECO2(iaq),
+		BVOC:     BVOC(iaq),
+	}, nil
+}
+
+func (e *Estimator) accuracy() Accuracy {
+	switch e.tracker.State() {
+	case gas.StateBurnIn:
+		return AccuracyLow
+	case gas.StateStable:
+		if e.tracker.Snapshot().Samples >= HighAccuracySamples {
+			return AccuracyHigh
+		}
+
+		return AccuracyMedium
+	}
+
+	return AccuracyUnreliable
+}
+
+// Index returns the index, from 0 (best) to 500 (worst), for the relative
+// humidity in percent and the gas resistance relative to its baseline. The
+// humidity score is highest at humidityBaseline, and weights humidityWeight
+// of the score. A baseline outside (0, 100) or a weight outside [0, 1] is
+// replaced by its default.
+func Index(humidity, gasRatio, humidityBaseline, humidityWeight float32) float32 {
+	if !validHumidityBaseline(humidityBaseline) {
+		humidityBaseline = HumidityBaseline
+	}
+
+	if !validHumidityWeight(humidityWeight) {
+		humidityWeight = HumidityWeight
+	}
+
+	var humScore float32
+
+	// linear from the baseline down to 0% or up to 100%
+	if offset := humidity - humidityBaseline; offset > 0 {
+		humScore = (100 - humidityBaseline - offset) / (100 - humidityBaseline)
+	} else {
+		humScore = (humidityBaseline + offset) / humidityBaseline
+	}
+
+	gasScore := min(max(gasRatio, 0), 1)
+	humScore = min(max(humScore, 0), 1)
+
+	score := humScore*humidityWeight + gasScore*(1-humidityWeight)
+
+	return (1 - score) * 500
+}
+
+// validHumidityBaseline reports whether the baseline leaves a humidity score
+// range on both sides, avoiding a division by zero.
+func validHumidityBaseline(humidity float32) bool {
+	return humidity > 0 && humidity < 100
+}
+
+func validHumidityWeight(weight float32) bool {
+	return weight >= 0 && weight <= 1
+}
+
+// iaqSteps are the indexes of the eCO2 and bVOC approximations.
+var iaqSteps = [...]float32{0, 50, 100, 150, 200, 300, 500}
+
+var (
+	eco2Steps = [...]float32{400, 600, 800, 1200, 1800, 3000, 5000}
+	bvocSteps = [...]float32{0.2, 0.5, 1, 2, 3.5, 7, 15}
+)
+
+// ECO2 returns an approximation of the equivalent CO2 in ppm for the index,
+// interpolated from 400ppm at 0 to 5000ppm at 500.
+func ECO2(iaq float32) float32 {
+	return interpolate(iaq, &eco2Steps)
+}
+
+// BVOC returns an approximation of the breath VOC in ppm for the index,
+// interpolated from 0.2ppm at 0 to 15ppm at 500.
+func BVOC(iaq float32) float32 {
+	return interpolate(iaq, &bvocSteps)
+}
+
+// interpolate returns the linear interpolation of values at the index.
+func interpolate(iaq float32, values *[len(iaqSteps)]float32) float32 {
+	if iaq <= iaqSteps[0] {
+		return values[0]
+	}
+
+	for i := 1; i < len(iaqSteps); i++ {
+		if iaq <= iaqSteps[i] {
+			t := (iaq - iaqSteps[i-1]) / (iaqSteps[i] - iaqSteps[i-1])
+
+			return values[i-1] + t*(values[i]-values[i-1])
+		}
+	}
+
+	return values[len(values)-1]
+}
@@ -0,0 +1,188 @@
+package iaq
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/gas"
+)
+
+// trace returns the measurements of a trace in testdata. The traces are
+// synthetic, written by testdata/gen.go, not captured from a sensor.
+func trace(t *testing.T, name string) []bme68x.Measurement {
+	t.Helper()
+
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, records, err := bme68x.DecodeRawData(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ms := make([]bme68x.Measurement, len(records))
+	for i, r := range records {
+		ms[i] = bme68x.Measurement{
+			Time:              r.Time,
+			Status:            bme68x.NEW_DATA_MSK,
+			GasIndex:          r.GasIndex,
+			TemperatureMilli:  int32(r.Temperature * 1000),
+			PressurePa:        uint32(r.Pressure * 100),
+			HumidityMilli:     uint32(r.Humidity * 1000),
+			GasResistanceOhms: uint32(r.GasResistance),
+		}
+
+		if r.GasValid {
+			ms[i].Status |= bme68x.GASM_VALID_MSK
+		}
+
+		if r.HeatStable {
+			ms[i].Status |= bme68x.HEAT_STAB_MSK
+		}
+	}
+
+	return ms
+}
+
+// phase is the expected estimates from the time since the start of a trace.
+type phase struct {
+	from     time.Duration
+	accuracy Accuracy
+	// the index stays within min and max
+	min, max float32
+}
+
+func TestEstimatorTraces(t *testing.T) {
+	settled := []phase{
+		{0, AccuracyUnreliable, 0, 50},
+		{gas.WarmUp, AccuracyLow, 0, 50},
+		{gas.WarmUp + gas.BurnIn, AccuracyMedium, 0, 50},
+	}
+
+	for _, c := range []struct {
+		name   string
+		phases []phase
+	}{
+		{"clean.bmerawdata", settled},
+		{"event.bmerawdata", append(settled[:len(settled):len(settled)],
+			// the resistance drops to a quarter from 45 to 52 minutes
+			phase{45 * time.Minute, AccuracyMedium, 0, 350},
+			phase{46 * time.Minute, AccuracyMedium, 150, 350},
+			phase{52 * time.Minute, AccuracyMedium, 0, 350},
+			phase{60 * time.Minute, AccuracyMedium, 0, 50},
+		)},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			ms := trace(t, c.name)
+			if len(ms) == 0 {
+				t.Fatal("empty trace")
+			}
+
+			e := New(gas.New())
+			start := ms[0].Time
+
+			for _, m := range ms {
+				est, err := e.Update(m)
+				if err != nil {
+					t.Fatalf("%v: %v", m.Time.Sub(start), err)
+				}
+
+				elapsed := m.Time.Sub(start)
+
+				// the last phase started
+				var p phase
+				for _, q := range c.phases {
+					if elapsed >= q.from {
+						p = q
+					}
+				}
+
+				if est.Accuracy != p.accuracy || est.IAQ < p.min || est.IAQ > p.max {
+					t.Errorf("%v: %v, want accuracy %v and IAQ in [%v, %v]", elapsed, est, p.accuracy, p.min, p.max)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimatorWithoutBaseline(t *testing.T) {
+	e := New(gas.New())
+	m := bme68x.Measurement{
+		Time:              time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		Status:            bme68x.NEW_DATA_MSK | bme68x.GASM_VALID_MSK | bme68x.HEAT_STAB_MSK,
+		HumidityMilli:     20000,
+		GasResistanceOhms: 50e3,
+	}
+
+	est, err := e.Update(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// half the humidity score, the gas not counting
+	if est.IAQ != 250 || est.Accuracy != AccuracyUnreliable {
+		t.Errorf("%v during the warm-up, want IAQ 250 and accuracy %v", est, AccuracyUnreliable)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	for _, c := range []struct {
+		name                       string
+		humidity, ratio, base, wgt float32
+		want                       float32
+	}{
+		{"best", 40, 1, HumidityBaseline, HumidityWeight, 0},
+		{"worst", 100, 0, HumidityBaseline, HumidityWeight, 500},
+		{"dry", 20, 1, HumidityBaseline, HumidityWeight, 62.5},
+		{"half gas", 40, 0.5, HumidityBaseline, HumidityWeight, 187.5},
+		{"gas only", 0, 1, HumidityBaseline, 0, 0},
+		// invalid baselines and weights fall back to the defaults
+		{"zero baseline", 40, 1, 0, HumidityWeight, 0},
+		{"full baseline", 40, 1, 100, HumidityWeight, 0},
+		{"NaN baseline", 40, 1, float32(math.NaN()), HumidityWeight, 0},
+		{"negative weight", 20, 1, HumidityBaseline, -1, 62.5},
+		{"weight above 1", 20, 1, HumidityBaseline, 2, 62.5},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := Index(c.humidity, c.ratio, c.base, c.wgt)
+			if math.Abs(float64(got-c.want)) > 1e-3 {
+				t.Errorf("Index(%v, %v, %v, %v) = %v, want %v", c.humidity, c.ratio, c.base, c.wgt, got, c.want)
+			}
+		})
+	}
+}
+
+func TestOptionsInvalid(t *testing.T) {
+	e := New(gas.New(), WithHumidityBaseline(0), WithHumidityBaseline(100), WithHumidityWeight(-0.5), WithHumidityWeight(1.5))
+
+	if e.humidityBaseline != HumidityBaseline || e.humidityWeight != HumidityWeight {
+		t.Errorf("baseline %v, weight %v, want the defaults %v, %v",
+			e.humidityBaseline, e.humidityWeight, HumidityBaseline, HumidityWeight)
+	}
+}
+
+func TestECO2BVOC(t *testing.T) {
+	for _, c := range []struct {
+		iaq, eco2, bvoc float32
+	}{
+		{-1, 400, 0.2},
+		{0, 400, 0.2},
+		{75, 700, 0.75},
+		{500, 5000, 15},
+		{600, 5000, 15},
+	} {
+		if got := ECO2(c.iaq); got != c.eco2 {
+			t.Errorf("ECO2(%v) = %v, want %v", c.iaq, got, c.eco2)
+		}
+
+		if got := BVOC(c.iaq); got != c.bvoc {
+			t.Errorf("BVOC(%v) = %v, want %v", c.iaq, got, c.bvoc)
+		}
+	}
+}
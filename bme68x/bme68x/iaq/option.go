@@ -0,0 +1,23 @@
+package iaq
+
+type Option func(*Estimator)
+
+// WithHumidityBaseline sets the optimal relative humidity in percent,
+// strictly between 0 and 100. Other values are ignored.
+func WithHumidityBaseline(humidity float32) Option {
+	return func(e *Estimator) {
+		if validHumidityBaseline(humidity) {
+			e.humidityBaseline = humidity
+		}
+	}
+}
+
+// WithHumidityWeight sets the weight of the humidity in the score, between 0
+// and 1, the gas having the rest. Other values are ignored.
+func WithHumidityWeight(weight float32) Option {
+	return func(e *Estimator) {
+		if validHumidityWeight(weight) {
+			e.humidityWeight = weight
+		}
+	}
+}
@@ -0,0 +1,113 @@
+//go:build ignore
+
+// Command gen writes the .bmerawdata traces of the iaq tests. The traces are
+// synthetic: a measurement every 10s of a BME688 in an office, the gas
+// resistance rising to its clean air level as the sensor warms up.
+//
+//	clean.bmerawdata	70 minutes of clean air
+//	event.bmerawdata	the same, with a VOC event from 45 to 52 minutes
+//
+// Usage:
+//
+//	go run gen.go
+package main
+
+import (
+	"log"
+	"math"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"BME68x/bme68x"
+)
+
+const (
+	period   = 10 * time.Second
+	duration = 70 * time.Minute
+	// clean is the gas resistance in clean air, in Ohms.
+	clean = 120e3
+)
+
+var created = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+
+// event returns the factor of the gas resistance during the VOC event,
+// dropping to a quarter in about 30s and recovering in about 3 minutes.
+func event(t time.Duration) float64 {
+	const start, end = 45 * time.Minute, 52 * time.Minute
+
+	switch {
+	case t < start:
+		return 1
+	case t < end:
+		return 0.25 + 0.75*math.Exp(-float64(t-start)/float64(30*time.Second))
+	}
+
+	return 1 - 0.75*math.Exp(-float64(t-end)/float64(3*time.Minute))
+}
+
+func write(name string, events bool) {
+	f, err := os.Create(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	e, err := bme68x.NewRawDataEncoder(f, bme68x.RawDataHeader{
+		BoardType:       "board_8",
+		BoardMode:       "data_logging",
+		BoardLayout:     "grouped",
+		BoardID:         "B8:D6:1A:00:00:01",
+		FirmwareVersion: "2.0.0",
+		Created:         created,
+		Config: bme68x.StudioConfig{
+			HeaterProfiles: []bme68x.StudioHeaterProfile{{
+				ID:       "heater_320",
+				TimeBase: 140,
+				Steps:    []bme68x.HeaterStep{{Temp: 320, Dur: 10}},
+			}},
+			DutyCycles: []bme68x.StudioDutyCycle{{ID: "duty_1", ScanningCycles: 1}},
+			Sensors:    []bme68x.StudioSensor{{Index: 0, Active: true, HeaterProfile: "heater_320", DutyCycle: "duty_1"}},
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rnd := rand.New(rand.NewPCG(1, 2))
+
+	for t := time.Duration(0); t < duration; t += period {
+		// the metal oxide settles in a few minutes after power on
+		r := clean * (1 - 0.6*math.Exp(-float64(t)/float64(3*time.Minute)))
+		if events {
+			r *= event(t)
+		}
+
+		r *= 1 + 0.01*rnd.NormFloat64()
+
+		err := e.Encode(bme68x.RawDataRecord{
+			SensorID:      3224510336,
+			Uptime:        t,
+			Time:          created.Add(t),
+			Temperature:   float32(22.5 + 0.05*rnd.NormFloat64()),
+			Pressure:      float32(1013.2 + 0.02*rnd.NormFloat64()),
+			Humidity:      float32(40 + 0.5*rnd.NormFloat64()),
+			GasResistance: float32(r),
+			Scanning:      true,
+			HeatStable:    true,
+			GasValid:      true,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := e.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	write("clean.bmerawdata", false)
+	write("event.bmerawdata", true)
+}
@@ -0,0 +1,284 @@
+// Command regen generates the typed register layer of the bme68x package from
+// the register description below.
+//
+// Usage:
+//
+//	go run ./internal/regen -o registers_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"text/template"
+)
+
+// field is a bit field of a register, from bit MSB down to bit LSB.
+type field struct {
+	// Name is the datasheet name of the field.
+	Name string
+	// Method is the name of the getter, the setter being Set<Method>.
+	Method string
+	MSB    uint8
+	LSB    uint8
+	// Type is the Go type of the value, uint8 when empty. Single bit fields
+	// may use bool.
+	Type string
+}
+
+// register is a register, or Count registers Stride bytes apart.
+type register struct {
+	// Name is the datasheet name of the register, suffixed with the index
+	// for an array.
+	Name string
+	// Type is the Go type holding the register value.
+	Type string
+	Addr uint8
+	// Page is the SPI memory page, MEM_PAGE0 for 0x00-0x7F and MEM_PAGE1
+	// for 0x80-0xFF.
+	Page   uint8
+	Count  uint8
+	Stride uint8
+	Fields []field
+	// ReadOnly registers are not written by the driver.
+	ReadOnly bool
+}
+
+var registers = []register{
+	{Name: "meas_status", Type: "regMeasStatus", Addr: 0x1D, Count: 3, Stride: 17, ReadOnly: true, Fields: []field{
+		{Name: "new_data", Method: "NewData", MSB: 7, LSB: 7, Type: "bool"},
+		{Name: "gas_measuring", Method: "GasMeasuring", MSB: 6, LSB: 6, Type: "bool"},
+		{Name: "measuring", Method: "Measuring", MSB: 5, LSB: 5, Type: "bool"},
+		{Name: "gas_meas_index", Method: "GasMeasIndex", MSB: 3, LSB: 0},
+	}},
+	{Name: "meas_index", Type: "regMeasIndex", Addr: 0x1E, Count: 3, Stride: 17, ReadOnly: true},
+	{Name: "idac_heat", Type: "regIdacHeat", Addr: 0x50, Count: 10, Stride: 1},
+	{Name: "res_heat", Type: "regResHeat", Addr: 0x5A, Count: 10, Stride: 1},
+	{Name: "gas_wait", Type: "regGasWait", Addr: 0x64, Count: 10, Stride: 1, Fields: []field{
+		{Name: "mult", Method: "Mult", MSB: 7, LSB: 6},
+		{Name: "value", Method: "Value", MSB: 5, LSB: 0},
+	}},
+	{Name: "shd_heatr_dur", Type: "regShdHeatrDur", Addr: 0x6E, Fields: []field{
+		{Name: "mult", Method: "Mult", MSB: 7, LSB: 6},
+		{Name: "value", Method: "Value", MSB: 5, LSB: 0},
+	}},
+	{Name: "ctrl_gas_0", Type: "regCtrlGas0", Addr: 0x70, Fields: []field{
+		{Name: "heat_off", Method: "HeatOff", MSB: 3, LSB: 3, Type: "bool"},
+	}},
+	{Name: "ctrl_gas_1", Type: "regCtrlGas1", Addr: 0x71, Fields: []field{
+		{Name: "odr3", Method: "ODR3", MSB: 7, LSB: 7, Type: "bool"},
+		{Name: "run_gas", Method: "RunGas", MSB: 5, LSB: 4},
+		{Name: "nb_conv", Method: "NbConv", MSB: 3, LSB: 0},
+	}},
+	{Name: "ctrl_hum", Type: "regCtrlHum", Addr: 0x72, Fields: []field{
+		{Name: "spi_3w_int_en", Method: "SPI3WIntEn", MSB: 6, LSB: 6, Type: "bool"},
+		{Name: "osrs_h", Method: "OSH", MSB: 2, LSB: 0, Type: "Oversampling"},
+	}},
+	{Name: "status", Type: "regStatus", Addr: 0x73, Fields: []field{
+		{Name: "spi_mem_page", Method: "SPIMemPage", MSB: 4, LSB: 4, Type: "bool"},
+	}},
+	{Name: "ctrl_meas", Type: "regCtrlMeas", Addr: 0x74, Fields: []field{
+		{Name: "osrs_t", Method: "OST", MSB: 7, LSB: 5, Type: "Oversampling"},
+		{Name: "osrs_p", Method: "OSP", MSB: 4, LSB: 2, Type: "Oversampling"},
+		{Name: "mode", Method: "Mode", MSB: 1, LSB: 0, Type: "Mode"},
+	}},
+	{Name: "config", Type: "regConfig", Addr: 0x75, Fields: []field{
+		{Name: "odr20", Method: "ODR20", MSB: 7, LSB: 5, Type: "ODR"},
+		{Name: "filter", Method: "Filter", MSB: 4, LSB: 2, Type: "FilterCoefficient"},
+		{Name: "spi_3w_en", Method: "SPI3WEn", MSB: 0, LSB: 0, Type: "bool"},
+	}},
+	{Name: "chip_id", Type: "regChipID", Addr: 0xD0, Page: 1, ReadOnly: true},
+	{Name: "variant_id", Type: "regVariantID", Addr: 0xF0, Page: 1, ReadOnly: true},
+}
+
+func (f field) Mask() uint8 {
+	return uint8(0xFF>>(7-f.MSB)) &^ (1<<f.LSB - 1)
+}
+
+func (f field) Bool() bool {
+	return f.Type == "bool"
+}
+
+func (f field) ValueType() string {
+	if f.Type == "" {
+		return "uint8"
+	}
+
+	return f.Type
+}
+
+func (f field) Bits() string {
+	if f.MSB == f.LSB {
+		return fmt.Sprintf("bit %d", f.MSB)
+	}
+
+	return fmt.Sprintf("bits %d:%d", f.MSB, f.LSB)
+}
+
+// Get returns the expression extracting the field from r.
+func (f field) Get() string {
+	expr := fmt.Sprintf("uint8(r) & 0x%02X", f.Mask())
+	if f.LSB != 0 {
+		expr = fmt.Sprintf("(%s) >> %d", expr, f.LSB)
+	}
+
+	if f.ValueType() == "uint8" {
+		return expr
+	}
+
+	return fmt.Sprintf("%s(%s)", f.ValueType(), expr)
+}
+
+// Set returns the expression placing v in the field.
+func (f field) Set() string {
+	expr := "uint8(v)"
+	if f.ValueType() == "uint8" {
+		expr = "v"
+	}
+
+	if f.LSB != 0 {
+		expr = fmt.Sprintf("(%s<<%d)", expr, f.LSB)
+	}
+
+	return fmt.Sprintf("%s&0x%02X", expr, f.Mask())
+}
+
+// instance is one register of the generated table.
+type instance struct {
+	Name string
+	Addr uint8
+	Page string
+	*register
+}
+
+func (r *register) Instances() []instance {
+	page := "MEM_PAGE0"
+	if r.Page == 1 {
+		page = "MEM_PAGE1"
+	}
+
+	if r.Count == 0 {
+		return []instance{{r.Name, r.Addr, page, r}}
+	}
+
+	var instances []instance
+	for i := uint8(0); i < r.Count; i++ {
+		instances = append(instances, instance{fmt.Sprintf("%s_%d", r.Name, i), r.Addr + i*r.Stride, page, r})
+	}
+
+	return instances
+}
+
+func (r *register) Array() bool {
+	return r.Count != 0
+}
+
+func (r *register) validate() error {
+	for _, in := range r.Instances() {
+		if (in.Addr > 0x7F) != (r.Page == 1) {
+			return fmt.Errorf("%s: address 0x%02X not in page %d", in.Name, in.Addr, r.Page)
+		}
+	}
+
+	var used uint8
+	for _, f := range r.Fields {
+		if f.MSB > 7 || f.LSB > f.MSB {
+			return fmt.Errorf("%s.%s: invalid bits %d:%d", r.Name, f.Name, f.MSB, f.LSB)
+		}
+
+		if f.Bool() && f.MSB != f.LSB {
+			return fmt.Errorf("%s.%s: bool field wider than a bit", r.Name, f.Name)
+		}
+
+		if used&f.Mask() != 0 {
+			return fmt.Errorf("%s.%s: overlapping fields", r.Name, f.Name)
+		}
+
+		used |= f.Mask()
+	}
+
+	return nil
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by regen; DO NOT EDIT.
+
+package bme68x
+
+{{range .}}{{$r := .}}
+// {{.Type}} is the {{.Name}} register{{if .Array}} array, {{.Count}} registers from 0x{{printf "%02X" .Addr}}{{else}} (0x{{printf "%02X" .Addr}}){{end}}.
+type {{.Type}} uint8
+{{if not .Array}}
+func ({{.Type}}) addr() uint8 { return 0x{{printf "%02X" .Addr}} }
+{{end}}{{range .Fields}}
+// {{.Method}} returns the {{.Name}} field, {{.Bits}}.
+func (r {{$r.Type}}) {{.Method}}() {{.ValueType}} {
+{{- if .Bool}}
+	return uint8(r)&0x{{printf "%02X" .Mask}} != 0
+{{- else}}
+	return {{.Get}}
+{{- end}}
+}
+{{if not $r.ReadOnly}}
+// Set{{.Method}} sets the {{.Name}} field, {{.Bits}}.
+func (r *{{$r.Type}}) Set{{.Method}}(v {{.ValueType}}) {
+{{- if .Bool}}
+	if v {
+		*r |= 0x{{printf "%02X" .Mask}}
+	} else {
+		*r &^= 0x{{printf "%02X" .Mask}}
+	}
+{{- else}}
+	*r = (*r &^ 0x{{printf "%02X" .Mask}}) | {{$r.Type}}({{.Set}})
+{{- end}}
+}
+{{end}}{{end}}{{end}}
+// Registers describes the registers of the sensor, with their bit fields.
+var Registers = []Register{
+{{- range .}}{{range .Instances}}
+	{Name: "{{.Name}}", Addr: 0x{{printf "%02X" .Addr}}, Page: {{.Page}}
+	{{- if .Fields}}, Fields: []RegisterField{
+	{{- range .Fields}}
+		{Name: "{{.Name}}", Mask: 0x{{printf "%02X" .Mask}}, Pos: {{.LSB}}},
+	{{- end}}
+	}{{end}}},
+{{- end}}{{end}}
+}
+`))
+
+func main() {
+	out := flag.String("o", "registers_gen.go", "output file")
+	flag.Parse()
+
+	src, err := generate(registers)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate validates the registers and returns the formatted source.
+func generate(registers []register) ([]byte, error) {
+	for i := range registers {
+		if err := registers[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, registers); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format: %w\n%s", err, buf.String())
+	}
+
+	return src, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGenerateUpToDate(t *testing.T) {
+	src, err := generate(registers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gen, err := os.ReadFile("../../registers_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(src, gen) {
+		t.Error("registers_gen.go is out of date, run go generate")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		reg  register
+		ok   bool
+	}{
+		{"valid", register{Name: "ctrl_meas", Addr: 0x74, Fields: []field{
+			{Name: "osrs_t", MSB: 7, LSB: 5},
+			{Name: "mode", MSB: 1, LSB: 0},
+		}}, true},
+		{"overlap", register{Name: "ctrl_meas", Addr: 0x74, Fields: []field{
+			{Name: "osrs_t", MSB: 7, LSB: 5},
+			{Name: "osrs_p", MSB: 5, LSB: 2},
+		}}, false},
+		{"MSB below LSB", register{Name: "ctrl_meas", Addr: 0x74, Fields: []field{
+			{Name: "osrs_t", MSB: 5, LSB: 7},
+		}}, false},
+		{"MSB above 7", register{Name: "ctrl_meas", Addr: 0x74, Fields: []field{
+			{Name: "osrs_t", MSB: 8, LSB: 5},
+		}}, false},
+		{"wide bool", register{Name: "ctrl_gas_0", Addr: 0x70, Fields: []field{
+			{Name: "heat_off", MSB: 3, LSB: 2, Type: "bool"},
+		}}, false},
+		{"page 1 address in page 0", register{Name: "chip_id", Addr: 0xD0}, false},
+		{"page 0 address in page 1", register{Name: "ctrl_meas", Addr: 0x74, Page: 1}, false},
+		{"array across the pages", register{Name: "gas_wait", Addr: 0x7C, Count: 10, Stride: 1}, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.reg.validate(); (err == nil) != c.ok {
+				t.Errorf("validate returned %v, want ok %v", err, c.ok)
+			}
+		})
+	}
+}
+
+func TestFieldMask(t *testing.T) {
+	for _, c := range []struct {
+		f    field
+		mask uint8
+	}{
+		{field{MSB: 7, LSB: 0}, 0xFF},
+		{field{MSB: 7, LSB: 5}, 0xE0},
+		{field{MSB: 4, LSB: 2}, 0x1C},
+		{field{MSB: 0, LSB: 0}, 0x01},
+	} {
+		if got := c.f.Mask(); got != c.mask {
+			t.Errorf("mask of bits %d:%d = 0x%02X, want 0x%02X", c.f.MSB, c.f.LSB, got, c.mask)
+		}
+	}
+}
@@ -65,6 +65,44 @@ func WithHeatrDuration(duration uint16) Option {
 	}
 }
 
+// WithHeatrProfile sets the heater profile.
+func WithHeatrProfile(steps ...HeaterStep) Option {
+	return func(d *Device) {
+		d.config.HeatrProfile = steps
+	}
+}
+
+// WithHeatrSharedDuration sets the heater duration in ms shared by the
+// profile steps in parallel mode.
+func WithHeatrSharedDuration(duration uint16) Option {
+	return func(d *Device) {
+		d.config.HeatrSharedDur = duration
+	}
+}
+
+// WithHeatrStep sets the heater profile step used in forced mode.
+func WithHeatrStep(step uint8) Option {
+	return func(d *Device) {
+		d.config.HeatrStep = step
+	}
+}
+
+// WithHeatrCycle makes Read cycle through the heater profile steps in
+// forced mode.
+func WithHeatrCycle(enable bool) Option {
+	return func(d *Device) {
+		d.config.HeatrCycle = enable
+	}
+}
+
+// WithIntCompensation selects the integer compensation, for targets without
+// FPU.
+func WithIntCompensation(enable bool) Option {
+	return func(d *Device) {
+		d.config.IntCompensation = enable
+	}
+}
+
 // WithAmbientTemperature sets the ambient temperature.
 // The temperature in deg C is used for defining the heater temperature.
 func WithAmbientTemperature(temp int8) Option {
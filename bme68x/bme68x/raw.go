@@ -0,0 +1,86 @@
+package bme68x
+
+import (
+	"fmt"
+	"time"
+)
+
+// Raw is an uncompensated measurement, as read from a field buffer. Use
+// Compensate or CompensateInt with the calibration of the sensor to get the
+// measurement.
+type Raw struct {
+	// Time is the time the measurement was read.
+	Time time.Time
+	// Status contains new_data, gasm_valid and heat_stab bits.
+	Status byte
+	// GasIndex is the index of the heater profile step used.
+	GasIndex uint8
+	// MeasIndex is the measurement index to track order.
+	MeasIndex uint8
+	// ResHeat is the heater resistance.
+	ResHeat uint8
+	// Idac is the current DAC.
+	Idac uint8
+	// GasWait is the gas wait period.
+	GasWait uint8
+	// Temperature is the 20-bit temperature ADC value.
+	Temperature uint32
+	// Pressure is the 20-bit pressure ADC value.
+	Pressure uint32
+	// Humidity is the 16-bit humidity ADC value.
+	Humidity uint16
+	// Gas is the 10-bit gas resistance ADC value.
+	Gas uint16
+	// GasRange is the gas resistance range.
+	GasRange uint8
+}
+
+// parseRaw decodes a field buffer. The gas registers depend on the variant.
+func parseRaw(data [LEN_FIELD]byte, variantID uint8) Raw {
+	raw := Raw{
+		Status:      data[0] & NEW_DATA_MSK,
+		GasIndex:    data[0] & GAS_INDEX_MSK,
+		MeasIndex:   data[1],
+		Pressure:    (uint32(data[2]) * 4096) | (uint32(data[3]) * 16) | (uint32(data[4]) / 16),
+		Temperature: (uint32(data[5]) * 4096) | (uint32(data[6]) * 16) | (uint32(data[7]) / 16),
+		Humidity:    uint16((uint32(data[8]) * 256) | (uint32(data[9]))),
+	}
+
+	gas := data[13:15]
+	if variantID == VARIANT_GAS_HIGH {
+		gas = data[15:17]
+	}
+
+	raw.Gas = uint16(uint32(gas[0])*4 | (uint32(gas[1]) / 64))
+	raw.GasRange = gas[1] & GAS_RANGE_MSK
+	raw.Status |= gas[1] & (GASM_VALID_MSK | HEAT_STAB_MSK)
+
+	return raw
+}
+
+// NewData reports whether the raw measurement holds new data.
+func (r Raw) NewData() bool {
+	return r.Status&NEW_DATA_MSK != 0
+}
+
+// measurement returns a measurement holding the fields copied as is.
+func (r Raw) measurement() Measurement {
+	return Measurement{
+		Time:      r.Time,
+		Status:    r.Status,
+		GasIndex:  r.GasIndex,
+		MeasIndex: r.MeasIndex,
+		ResHeat:   r.ResHeat,
+		Idac:      r.Idac,
+		GasWait:   r.GasWait,
+	}
+}
+
+// String implements fmt.Stringer interface.
+func (r Raw) String() string {
+	return fmt.Sprintf("status: 0x%X, gas index: %d, meas index: %d, temperature: %d, pressure: %d,"+
+		" humidity: %d, gas: %d, gas range: %d, res heat: %d, gas wait: %d, idac: %d",
+		r.Status, r.GasIndex, r.MeasIndex, r.Temperature, r.Pressure,
+		r.Humidity, r.Gas, r.GasRange, r.ResHeat, r.GasWait, r.Idac,
+	)
+}
@@ -0,0 +1,363 @@
+package bme68x
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// rawDataTimeLayout is the layout of the creation dates.
+const rawDataTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// ErrRawDataClosed is returned when encoding a record after Close.
+var ErrRawDataClosed = errors.New("raw data encoder closed")
+
+// RawDataHeader is the metadata of a .bmerawdata file, the format recorded
+// by the Bosch BME688 development kit and imported by AI Studio.
+type RawDataHeader struct {
+	// BoardType, BoardMode and BoardLayout describe the board as in AI
+	// Studio, such as "board_8", "live_test_algorithm" and "grouped".
+	BoardType   string
+	BoardMode   string
+	BoardLayout string
+	// BoardID identifies the board, such as its MAC address.
+	BoardID         string
+	FirmwareVersion string
+	// Created is the creation time of the file.
+	Created time.Time
+	// Config holds the heater profiles and duty cycles of the sensors.
+	Config StudioConfig
+}
+
+// RawDataRecord is a data row of a .bmerawdata file.
+type RawDataRecord struct {
+	// SensorIndex is the index of the sensor on the board.
+	SensorIndex int
+	// SensorID is the unique ID of the sensor.
+	SensorID uint32
+	// Uptime is the time since power on, with a millisecond resolution.
+	Uptime time.Duration
+	// Time is the real time of the measurement, with a second resolution.
+	// The zero time is recorded as 0.
+	Time time.Time
+	// Temperature is the temperature in degree Celsius.
+	Temperature float32
+	// Pressure is the pressure in hPa.
+	Pressure float32
+	// Humidity is the relative humidity in percent.
+	Humidity float32
+	// GasResistance is the gas resistance in Ohms.
+	GasResistance float32
+	// GasIndex is the index of the heater profile step.
+	GasIndex uint8
+	// Scanning reports whether the heater profile was running, and not
+	// sleeping as set by the duty cycle.
+	Scanning bool
+	// ScanningCycle is the index of the scanning cycle in the duty cycle.
+	ScanningCycle int
+	// Label is the label tag of the recording.
+	Label int
+	// ErrorCode is the error code of the board, 0 when none.
+	ErrorCode int
+	// HeatStable and GasValid are the heat_stab and gasm_valid flags.
+	HeatStable bool
+	GasValid   bool
+}
+
+// NewRawDataRecord returns the record of a measurement of the sensor at the
+// board index. The fields not part of the measurement are left to the
+// caller.
+func NewRawDataRecord(sensorIndex int, m Measurement) RawDataRecord {
+	return RawDataRecord{
+		SensorIndex:   sensorIndex,
+		Time:          m.Time,
+		Temperature:   float32(m.TemperatureMilli) / 1000,
+		Pressure:      float32(m.PressurePa) / 100,
+		Humidity:      float32(m.HumidityMilli) / 1000,
+		GasResistance: float32(m.GasResistanceOhms),
+		GasIndex:      m.GasIndex,
+		Scanning:      true,
+		HeatStable:    m.HeatStable(),
+		GasValid:      m.GasValid(),
+	}
+}
+
+// rawDataColumn is a column of the data block.
+type rawDataColumn struct {
+	Name   string `json:"name"`
+	Unit   string `json:"unit"`
+	Format string `json:"format"`
+	Key    string `json:"key"`
+	ColID  int    `json:"colId"`
+}
+
+// rawDataColumns are the columns written by RawDataEncoder, in order.
+var rawDataColumns = []rawDataColumn{
+	{Name: "Sensor Index", Format: "integer", Key: "sensor_index", ColID: 1},
+	{Name: "Sensor ID", Format: "integer", Key: "sensor_id", ColID: 2},
+	{Name: "Time Since PowerOn", Unit: "Milliseconds", Format: "integer", Key: "timestamp_since_poweron", ColID: 3},
+	{Name: "Real time clock", Unit: "Unix Timestamp: seconds since Jan 01 1970. (UTC)", Format: "integer", Key: "real_time_clock", ColID: 4},
+	{Name: "Temperature", Unit: "DegreesCelcius", Format: "float", Key: "temperature", ColID: 5},
+	{Name: "Pressure", Unit: "Hectopascals", Format: "float", Key: "pressure", ColID: 6},
+	{Name: "Relative Humidity", Unit: "Percent", Format: "float", Key: "relative_humidity", ColID: 7},
+	{Name: "Resistance Gassensor", Unit: "Ohms", Format: "float", Key: "resistance_gassensor", ColID: 8},
+	{Name: "Heater Profile Step Index", Format: "integer", Key: "heater_profile_step_index", ColID: 9},
+	{Name: "Scanning Mode Enabled", Format: "integer", Key: "scanning_enabled", ColID: 10},
+	{Name: "Scanning Cycle Index", Format: "integer", Key: "scanning_cycle_index", ColID: 11},
+	{Name: "Label Tag", Format: "integer", Key: "label_tag", ColID: 12},
+	{Name: "Error Code", Format: "integer", Key: "error_code", ColID: 13},
+	{Name: "Heater Stable", Format: "integer", Key: "heater_stable", ColID: 14},
+	{Name: "Gas Valid", Format: "integer", Key: "gas_valid", ColID: 15},
+}
+
+// rawDataFile is the JSON layout of a .bmerawdata file.
+type rawDataFile struct {
+	ConfigHeader  rawDataConfigHeader `json:"configHeader"`
+	ConfigBody    studioBody          `json:"configBody"`
+	RawDataHeader rawDataHeader       `json:"rawDataHeader"`
+	RawDataBody   *rawDataBody        `json:"rawDataBody,omitempty"`
+}
+
+type rawDataConfigHeader struct {
+	DateCreated string `json:"dateCreated_ISO"`
+	BoardType   string `json:"boardType"`
+	BoardMode   string `json:"boardMode"`
+	BoardLayout string `json:"boardLayout"`
+}
+
+type rawDataHeader struct {
+	DateCreated     string          `json:"dateCreated"`
+	DateCreatedISO  string          `json:"dateCreated_ISO"`
+	FirmwareVersion string          `json:"firmwareVersion"`
+	BoardID         string          `json:"boardId"`
+	DataColumns     []rawDataColumn `json:"dataColumns"`
+}
+
+type rawDataBody struct {
+	DataBlock [][]float64 `json:"dataBlock"`
+}
+
+// RawDataEncoder writes a .bmerawdata file as the records come. The file is
+// complete once closed.
+type RawDataEncoder struct {
+	w    io.Writer
+	buf  []byte
+	rows int
+	err  error
+}
+
+// NewRawDataEncoder writes the header to w and returns an encoder for the
+// records.
+func NewRawDataEncoder(w io.Writer, h RawDataHeader) (*RawDataEncoder, error) {
+	created := h.Created.UTC().Format(rawDataTimeLayout)
+
+	header, err := json.MarshalIndent(rawDataFile{
+		ConfigHeader: rawDataConfigHeader{
+			DateCreated: created,
+			BoardType:   h.BoardType,
+			BoardMode:   h.BoardMode,
+			BoardLayout: h.BoardLayout,
+		},
+		ConfigBody: h.Config.body(),
+		RawDataHeader: rawDataHeader{
+			DateCreated:     strconv.FormatInt(h.Created.Unix(), 10),
+			DateCreatedISO:  created,
+			FirmwareVersion: h.FirmwareVersion,
+			BoardID:         h.BoardID,
+			DataColumns:     rawDataColumns,
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode header: %w", err)
+	}
+
+	// leave the object open for the data block
+	header = bytes.TrimSuffix(header, []byte("\n}"))
+	header = append(header, ",\n  \"rawDataBody\": {\n    \"dataBlock\": ["...)
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &RawDataEncoder{w: w}, nil
+}
+
+// Encode writes a record. The values must be finite.
+func (e *RawDataEncoder) Encode(r RawDataRecord) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	for _, v := range [...]float32{r.Temperature, r.Pressure, r.Humidity, r.GasResistance} {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return fmt.Errorf("failed to encode record: invalid value %v", v)
+		}
+	}
+
+	var rtc int64
+	if !r.Time.IsZero() {
+		rtc = r.Time.Unix()
+	}
+
+	buf := e.buf[:0]
+	if e.rows > 0 {
+		buf = append(buf, ',')
+	}
+
+	buf = append(buf, "\n      ["...)
+	buf = strconv.AppendInt(buf, int64(r.SensorIndex), 10)
+	buf = append(buf, ", "...)
+	buf = strconv.AppendUint(buf, uint64(r.SensorID), 10)
+	buf = append(buf, ", "...)
+	buf = strconv.AppendInt(buf, r.Uptime.Milliseconds(), 10)
+	buf = append(buf, ", "...)
+	buf = strconv.AppendInt(buf, rtc, 10)
+
+	for _, v := range [...]float32{r.Temperature, r.Pressure, r.Humidity, r.GasResistance} {
+		buf = append(buf, ", "...)
+		buf = strconv.AppendFloat(buf, float64(v), 'f', -1, 32)
+	}
+
+	for _, v := range [...]int{int(r.GasIndex), boolToInt(r.Scanning), r.ScanningCycle, r.Label, r.ErrorCode, boolToInt(r.HeatStable), boolToInt(r.GasValid)} {
+		buf = append(buf, ", "...)
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	}
+
+	buf = append(buf, ']')
+	e.buf = buf
+
+	if _, err := e.w.Write(buf); err != nil {
+		e.err = err
+		return err
+	}
+
+	e.rows++
+
+	return nil
+}
+
+// EncodeMeasurement writes the record of a measurement of the sensor at the
+// board index, as returned by NewRawDataRecord.
+func (e *RawDataEncoder) EncodeMeasurement(sensorIndex int, m Measurement) error {
+	return e.Encode(NewRawDataRecord(sensorIndex, m))
+}
+
+// Close completes the file. It does not close the underlying writer.
+func (e *RawDataEncoder) Close() error {
+	if e.err != nil {
+		if e.err == ErrRawDataClosed {
+			return nil
+		}
+
+		return e.err
+	}
+
+	e.err = ErrRawDataClosed
+
+	_, err := io.WriteString(e.w, "\n    ]\n  }\n}\n")
+
+	return err
+}
+
+// DecodeRawData reads a .bmerawdata file. The columns are matched by key, so
+// files recorded by the development kit are read as well; the columns it does
+// not have are left to their zero value.
+func DecodeRawData(r io.Reader) (RawDataHeader, []RawDataRecord, error) {
+	var (
+		file rawDataFile
+		h    RawDataHeader
+	)
+
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return h, nil, fmt.Errorf("failed to decode bmerawdata: %w", err)
+	}
+
+	config, err := file.ConfigBody.config()
+	if err != nil {
+		return h, nil, err
+	}
+
+	h = RawDataHeader{
+		BoardType:       file.ConfigHeader.BoardType,
+		BoardMode:       file.ConfigHeader.BoardMode,
+		BoardLayout:     file.ConfigHeader.BoardLayout,
+		BoardID:         file.RawDataHeader.BoardID,
+		FirmwareVersion: file.RawDataHeader.FirmwareVersion,
+		Config:          config,
+	}
+
+	if created := file.RawDataHeader.DateCreatedISO; created != "" {
+		if h.Created, err = time.Parse(time.RFC3339, created); err != nil {
+			return h, nil, fmt.Errorf("failed to decode creation date: %w", err)
+		}
+	}
+
+	if file.RawDataBody == nil {
+		return h, nil, nil
+	}
+
+	columns := file.RawDataHeader.DataColumns
+	records := make([]RawDataRecord, 0, len(file.RawDataBody.DataBlock))
+
+	for i, row := range file.RawDataBody.DataBlock {
+		if len(row) != len(columns) {
+			return h, records, fmt.Errorf("failed to decode row %d: %d values for %d columns", i, len(row), len(columns))
+		}
+
+		var rec RawDataRecord
+
+		for j, v := range row {
+			switch columns[j].Key {
+			case "sensor_index":
+				rec.SensorIndex = int(v)
+			case "sensor_id":
+				rec.SensorID = uint32(v)
+			case "timestamp_since_poweron":
+				rec.Uptime = time.Duration(v) * time.Millisecond
+			case "real_time_clock":
+				if v != 0 {
+					rec.Time = time.Unix(int64(v), 0)
+				}
+			case "temperature":
+				rec.Temperature = float32(v)
+			case "pressure":
+				rec.Pressure = float32(v)
+			case "relative_humidity":
+				rec.Humidity = float32(v)
+			case "resistance_gassensor":
+				rec.GasResistance = float32(v)
+			case "heater_profile_step_index":
+				rec.GasIndex = uint8(v)
+			case "scanning_enabled":
+				rec.Scanning = v != 0
+			case "scanning_cycle_index":
+				rec.ScanningCycle = int(v)
+			case "label_tag":
+				rec.Label = int(v)
+			case "error_code":
+				rec.ErrorCode = int(v)
+			case "heater_stable":
+				rec.HeatStable = v != 0
+			case "gas_valid":
+				rec.GasValid = v != 0
+			}
+		}
+
+		records = append(records, rec)
+	}
+
+	return h, records, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,168 @@
+package bme68x
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testRawDataHeader = RawDataHeader{
+	BoardType:       "board_8",
+	BoardMode:       "live_test_algorithm",
+	BoardLayout:     "grouped",
+	BoardID:         "B8:D6:1A:00:00:01",
+	FirmwareVersion: "2.0.0",
+	Created:         time.Date(2024, 3, 1, 12, 30, 15, 250e6, time.UTC),
+	Config: StudioConfig{
+		HeaterProfiles: []StudioHeaterProfile{{
+			ID:       "heater_354",
+			TimeBase: 140,
+			Steps:    []HeaterStep{{Temp: 320, Dur: 5}, {Temp: 100, Dur: 2}, {Temp: 200, Dur: 10}},
+		}},
+		DutyCycles: []StudioDutyCycle{{ID: "duty_1", ScanningCycles: 1, SleepingCycles: 0}},
+		Sensors:    []StudioSensor{{Index: 0, Active: true, HeaterProfile: "heater_354", DutyCycle: "duty_1"}},
+	},
+}
+
+var testRawDataRecords = []RawDataRecord{
+	{
+		SensorIndex: 0, SensorID: 3224510336, Uptime: 1500 * time.Millisecond, Time: time.Unix(1709296216, 0),
+		Temperature: 24.83, Pressure: 1002.17, Humidity: 41.5, GasResistance: 123456.7,
+		GasIndex: 0, Scanning: true, HeatStable: true, GasValid: true,
+	},
+	{
+		SensorIndex: 0, SensorID: 3224510336, Uptime: 1640 * time.Millisecond, Time: time.Unix(1709296216, 0),
+		Temperature: -5.25, Pressure: 998, Humidity: 0, GasResistance: 9.5e6,
+		GasIndex: 1, Scanning: true, ScanningCycle: 2, Label: 3, ErrorCode: 0, HeatStable: false, GasValid: true,
+	},
+	// the zero time is recorded as 0
+	{SensorIndex: 7, Uptime: 4 * time.Hour, GasIndex: 9, ErrorCode: 12},
+}
+
+func encodeRawData(t *testing.T, h RawDataHeader, records []RawDataRecord) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	e, err := NewRawDataEncoder(&buf, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range records {
+		if err := e.Encode(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestRawDataRoundTrip(t *testing.T) {
+	for _, c := range []struct {
+		name    string
+		records []RawDataRecord
+	}{
+		{"records", testRawDataRecords},
+		{"no records", nil},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			data := encodeRawData(t, testRawDataHeader, c.records)
+
+			h, records, err := DecodeRawData(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("DecodeRawData: %v\n%s", err, data)
+			}
+
+			if !h.Created.Equal(testRawDataHeader.Created) {
+				t.Errorf("created %v, want %v", h.Created, testRawDataHeader.Created)
+			}
+
+			h.Created = testRawDataHeader.Created
+			if !reflect.DeepEqual(h, testRawDataHeader) {
+				t.Errorf("header %+v, want %+v", h, testRawDataHeader)
+			}
+
+			if len(records) != len(c.records) {
+				t.Fatalf("%d records, want %d", len(records), len(c.records))
+			}
+
+			for i, r := range records {
+				want := c.records[i]
+
+				if !r.Time.Equal(want.Time) {
+					t.Errorf("record %d: time %v, want %v", i, r.Time, want.Time)
+				}
+
+				r.Time, want.Time = time.Time{}, time.Time{}
+				if r != want {
+					t.Errorf("record %d: %+v, want %+v", i, r, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRawDataEncodeInvalid(t *testing.T) {
+	var buf bytes.Buffer
+
+	e, err := NewRawDataEncoder(&buf, testRawDataHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []float32{float32(math.NaN()), float32(math.Inf(1)), float32(math.Inf(-1))} {
+		r := testRawDataRecords[0]
+		r.Temperature = v
+
+		n := buf.Len()
+		if err := e.Encode(r); err == nil {
+			t.Errorf("Encode of temperature %v succeeded", v)
+		} else if buf.Len() != n {
+			t.Errorf("Encode of temperature %v wrote %q", v, buf.Bytes()[n:])
+		}
+	}
+
+	// the rejected records leave the file valid
+	if err := e.Encode(testRawDataRecords[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Encode(testRawDataRecords[0]); err != ErrRawDataClosed {
+		t.Errorf("Encode after Close returned %v, want %v", err, ErrRawDataClosed)
+	}
+
+	if _, records, err := DecodeRawData(&buf); err != nil || len(records) != 1 {
+		t.Errorf("DecodeRawData returned %d records, %v, want 1 record", len(records), err)
+	}
+}
+
+func TestDecodeRawDataInvalid(t *testing.T) {
+	valid := string(encodeRawData(t, testRawDataHeader, testRawDataRecords[:1]))
+
+	for _, c := range []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"truncated", valid[:len(valid)/2]},
+		{"short row", strings.Replace(valid, ", 1, 1]", ", 1]", 1)},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if _, _, err := DecodeRawData(strings.NewReader(c.data)); err == nil {
+				t.Errorf("DecodeRawData of %q succeeded", c.data)
+			}
+		})
+	}
+}
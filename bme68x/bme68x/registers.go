@@ -24,6 +24,14 @@ const (
 	REG_COEFF2 uint8 = 0xE1
 	// REG_COEFF3 is the register address for 3rd group of coefficients
 	REG_COEFF3 uint8 = 0x00
+	// LEN_COEFF1 is the length of the 1st group of coefficients
+	LEN_COEFF1 uint8 = 23
+	// LEN_COEFF2 is the length of the 2nd group of coefficients
+	LEN_COEFF2 uint8 = 14
+	// LEN_COEFF3 is the length of the 3rd group of coefficients
+	LEN_COEFF3 uint8 = 5
+	// LEN_COEFF_ALL is the length of the calibration data
+	LEN_COEFF_ALL = LEN_COEFF1 + LEN_COEFF2 + LEN_COEFF3
 
 	// REG_IDAC_HEAT0 is the 0th current DAC address
 	REG_IDAC_HEAT0 uint8 = 0x50 // idac_heat0
@@ -31,6 +39,8 @@ const (
 	REG_GAS_WAIT0 uint8 = 0x64 // gas_wait_0
 	// REG_RES_HEAT0 is the 0th resistance heat address
 	REG_RES_HEAT0 uint8 = 0x5A // res_heat_0
+	// REG_SHD_HEATR_DUR is the shared heater duration address
+	REG_SHD_HEATR_DUR uint8 = 0x6E // shd_heatr_dur
 	// REG_CTRL_GAS_0 is the CTRL_GAS_0 address
 	REG_CTRL_GAS_0 uint8 = 0x70 // ctrl_gas_0
 	// REG_CTRL_GAS_1 is the CTRL_GAS_1 address
@@ -38,8 +48,13 @@ const (
 	// REG_VARIANT_ID is the variant ID address
 	REG_VARIANT_ID uint8 = 0xF0 // variant_id
 
-	// MEAS_STATUS_0 is the measurement status address
+	// MEAS_STATUS_0 is the measurement status address of the first field
 	MEAS_STATUS_0 uint8 = 0x1D
+	// LEN_FIELD is the length of a field buffer, the fields follow each other
+	// from MEAS_STATUS_0 (0x1D, 0x2E and 0x3F)
+	LEN_FIELD uint8 = 17
+	// N_FIELDS is the number of field buffers
+	N_FIELDS = 3
 	// NEW_DATA_MSK is the mask for new data
 	NEW_DATA_MSK uint8 = 0x80
 	// GAS_INDEX_MSK is the mask for gas index
@@ -110,6 +125,12 @@ const (
 	// ModeForced is the forced mode. The sensor will take a measurement and store it in the
 	// sensor's memory.
 	ModeForced Mode = 0x01
+	// ModeParallel is the parallel mode (BME688 only). The sensor measures continuously,
+	// stepping through the heater profile while the TPH measurements run.
+	ModeParallel Mode = 0x02
+	// ModeSequential is the sequential mode (BME688 only). The sensor measures continuously,
+	// running one TPHG measurement per heater profile step.
+	ModeSequential Mode = 0x03
 )
 
 // FilterCoefficient is the filter coefficient used for the sensor.
@@ -0,0 +1,70 @@
+package bme68x
+
+import (
+	"fmt"
+	"strings"
+)
+
+//go:generate go run ./internal/regen -o registers_gen.go
+
+// Register describes a register of the sensor. Page is the SPI memory page
+// holding it, MEM_PAGE0 or MEM_PAGE1.
+type Register struct {
+	Name   string
+	Addr   uint8
+	Page   uint8
+	Fields []RegisterField
+}
+
+// RegisterField is a bit field of a register.
+type RegisterField struct {
+	Name string
+	Mask uint8
+	Pos  uint8
+}
+
+// Get returns the field value in the register value.
+func (f RegisterField) Get(value uint8) uint8 {
+	return (value & f.Mask) >> f.Pos
+}
+
+// Format returns the register value followed by every field, such as
+// "ctrl_meas (0x74) = 0x55: osrs_t=2 osrs_p=5 mode=1".
+func (r Register) Format(value uint8) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s (0x%02X) = 0x%02X", r.Name, r.Addr, value)
+
+	for i, f := range r.Fields {
+		sep := " "
+		if i == 0 {
+			sep = ": "
+		}
+
+		fmt.Fprintf(&sb, "%s%s=%d", sep, f.Name, f.Get(value))
+	}
+
+	return sb.String()
+}
+
+// typedRegister is implemented by the generated register types.
+type typedRegister interface {
+	~uint8
+	addr() uint8
+}
+
+// updateRegister reads the register, lets set change its fields and writes
+// it back.
+func updateRegister[R typedRegister](d *Device, set func(*R)) error {
+	var r R
+
+	value, err := d.readReg(r.addr())
+	if err != nil {
+		return err
+	}
+
+	r = R(value)
+	set(&r)
+
+	return d.writeReg(r.addr(), uint8(r))
+}
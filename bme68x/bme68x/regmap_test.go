@@ -0,0 +1,145 @@
+package bme68x
+
+import "testing"
+
+// memI2C is an I2C bus over a register memory, without side effects.
+type memI2C struct {
+	mem [256]byte
+}
+
+func (b *memI2C) Tx(addr uint16, w, r []byte) error {
+	if len(w) == 1 {
+		copy(r, b.mem[w[0]:])
+		return nil
+	}
+
+	for i := 0; i+1 < len(w); i += 2 {
+		b.mem[w[i]] = w[i+1]
+	}
+
+	return nil
+}
+
+// register returns the register with the name in Registers.
+func register(t *testing.T, name string) Register {
+	t.Helper()
+
+	for _, r := range Registers {
+		if r.Name == name {
+			return r
+		}
+	}
+
+	t.Fatalf("no register %q", name)
+
+	return Register{}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		value uint8
+		want  string
+	}{
+		{"ctrl_meas", 0x55, "ctrl_meas (0x74) = 0x55: osrs_t=2 osrs_p=5 mode=1"},
+		{"ctrl_hum", 0x45, "ctrl_hum (0x72) = 0x45: spi_3w_int_en=1 osrs_h=5"},
+		{"gas_wait_3", 0x59, "gas_wait_3 (0x67) = 0x59: mult=1 value=25"},
+		{"chip_id", CHIP_ID, "chip_id (0xD0) = 0x61"},
+	} {
+		if got := register(t, c.name).Format(c.value); got != c.want {
+			t.Errorf("Format(0x%02X) = %q, want %q", c.value, got, c.want)
+		}
+	}
+}
+
+// TestRegistersConstants checks the generated table against the constants
+// used by the driver.
+func TestRegistersConstants(t *testing.T) {
+	for _, c := range []struct {
+		reg, field string
+		addr       uint8
+		mask, pos  uint8
+	}{
+		{"meas_status_0", "new_data", MEAS_STATUS_0, NEW_DATA_MSK, 7},
+		{"meas_status_0", "gas_meas_index", MEAS_STATUS_0, GAS_INDEX_MSK, 0},
+		{"ctrl_gas_0", "heat_off", REG_CTRL_GAS_0, HCTRL_MSK, HCTRL_POS},
+		{"ctrl_gas_1", "odr3", REG_CTRL_GAS_1, ODR3_MSK, ODR3_POS},
+		{"ctrl_gas_1", "run_gas", REG_CTRL_GAS_1, RUN_GAS_MSK, RUN_GAS_POS},
+		{"ctrl_gas_1", "nb_conv", REG_CTRL_GAS_1, NBCONV_MSK, 0},
+		{"ctrl_hum", "osrs_h", REG_CTRL_HUM, OSH_MSK, 0},
+		{"status", "spi_mem_page", REG_MEM_PAGE & SPI_WR_MSK, MEM_PAGE_MSK, 4},
+		{"ctrl_meas", "osrs_t", REG_CTRL_MEAS, OST_MSK, OST_POS},
+		{"ctrl_meas", "osrs_p", REG_CTRL_MEAS, OSP_MSK, OSP_POS},
+		{"ctrl_meas", "mode", REG_CTRL_MEAS, MODE_MSK, 0},
+		{"config", "odr20", REG_CONFIG, ODR20_MSK, ODR20_POS},
+		{"config", "filter", REG_CONFIG, FILTER_MSK, FILTER_POS},
+		{"res_heat_0", "", REG_RES_HEAT0, 0, 0},
+		{"gas_wait_0", "value", REG_GAS_WAIT0, 0x3F, 0},
+		{"idac_heat_0", "", REG_IDAC_HEAT0, 0, 0},
+		{"shd_heatr_dur", "value", REG_SHD_HEATR_DUR, 0x3F, 0},
+		{"chip_id", "", REG_CHIP_ID, 0, 0},
+		{"variant_id", "", REG_VARIANT_ID, 0, 0},
+	} {
+		r := register(t, c.reg)
+		if r.Addr != c.addr {
+			t.Errorf("%s at 0x%02X, want 0x%02X", c.reg, r.Addr, c.addr)
+		}
+
+		page := MEM_PAGE0
+		if c.addr > 0x7F {
+			page = MEM_PAGE1
+		}
+
+		if r.Page != page {
+			t.Errorf("%s in page 0x%02X, want 0x%02X", c.reg, r.Page, page)
+		}
+
+		if c.field == "" {
+			continue
+		}
+
+		var found bool
+		for _, f := range r.Fields {
+			if f.Name == c.field {
+				found = true
+
+				if f.Mask != c.mask || f.Pos != c.pos {
+					t.Errorf("%s.%s mask 0x%02X at %d, want 0x%02X at %d", c.reg, c.field, f.Mask, f.Pos, c.mask, c.pos)
+				}
+			}
+		}
+
+		if !found {
+			t.Errorf("%s without field %s", c.reg, c.field)
+		}
+	}
+}
+
+func TestRegisterFieldGet(t *testing.T) {
+	f := RegisterField{Name: "osrs_p", Mask: OSP_MSK, Pos: OSP_POS}
+
+	for value, want := range map[uint8]uint8{0x00: 0, 0x55: 5, 0xFF: 7, 0xE3: 0} {
+		if got := f.Get(value); got != want {
+			t.Errorf("Get(0x%02X) = %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestUpdateRegister(t *testing.T) {
+	bus := &memI2C{}
+	bus.mem[REG_CTRL_MEAS] = 0x55
+
+	d := NewI2C(bus)
+
+	err := updateRegister(d, func(r *regCtrlMeas) {
+		r.SetOST(Sampling8X)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only osrs_t changes
+	if got, want := bus.mem[REG_CTRL_MEAS], uint8(Sampling8X)<<OST_POS|0x15; got != want {
+		t.Errorf("ctrl_meas 0x%02X, want 0x%02X", got, want)
+	}
+}
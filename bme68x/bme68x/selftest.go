@@ -0,0 +1,206 @@
+package bme68x
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// SelfTestMeasurements is the number of measurements alternating the
+	// heater temperature during the self-test.
+	SelfTestMeasurements = 6
+
+	selfTestLowTemp   uint16 = 150
+	selfTestHighTemp  uint16 = 350
+	selfTestHeatrDur1 uint16 = 1000
+	selfTestHeatrDur2 uint16 = 2000
+
+	selfTestMinTemperature float32 = 0
+	selfTestMaxTemperature float32 = 60
+	selfTestMinPressure    float32 = 90000
+	selfTestMaxPressure    float32 = 110000
+	selfTestMinHumidity    float32 = 20
+	selfTestMaxHumidity    float32 = 80
+	// selfTestMinGasRatio is the minimum ratio between the mean gas resistance
+	// at the high heater temperature and the one at the low temperature in
+	// between.
+	selfTestMinGasRatio float32 = 1.2
+)
+
+type (
+	// SelfTestCheck is the result of a single self-test check.
+	SelfTestCheck struct {
+		// Name describes the check.
+		Name string
+		// Passed is true when Value is within [Min, Max].
+		Passed bool
+		Value  float32
+		Min    float32
+		Max    float32
+	}
+
+	// SelfTestReport is the result of the self-test.
+	SelfTestReport struct {
+		// Heater checks that the heater current DAC is neither 0x00 nor 0xFF
+		// and the gas measurement is valid after heating at 350°C for 1s.
+		Heater SelfTestCheck
+		// Temperature, Pressure and Humidity check the ambient conditions
+		// are plausible.
+		Temperature SelfTestCheck
+		Pressure    SelfTestCheck
+		Humidity    SelfTestCheck
+		// GasValid counts the valid gas measurements.
+		GasValid SelfTestCheck
+		// GasRatio compares the gas resistance at 350°C and 150°C.
+		GasRatio SelfTestCheck
+		// Measurements alternate the heater between 150°C and 350°C.
+		Measurements [SelfTestMeasurements]Measurement
+	}
+)
+
+// SelfTest runs the self-test of Bosch's reference API. It heats at 350°C,
+// then alternates between 150°C and 350°C, checking the heater current, the
+// gas resistance ratio and the T/P/H ranges. It takes about 13s and the
+// configuration is restored afterwards.
+//
+// A failed check is reported in the returned report, an error is only
+// returned when the sensor can not be driven.
+func (d *Device) SelfTest() (SelfTestReport, error) {
+	saved := *d.config
+
+	report, err := d.selfTest()
+
+	// restore the configuration whatever the outcome
+	*d.config = saved
+
+	if rerr := d.applyConfig(context.Background()); rerr != nil && err == nil {
+		err = fmt.Errorf("failed to apply config: %w", rerr)
+	}
+
+	if rerr := d.applyGasConfig(context.Background()); rerr != nil && err == nil {
+		err = fmt.Errorf("failed to apply gas config: %w", rerr)
+	}
+
+	return report, err
+}
+
+func (d *Device) selfTest() (SelfTestReport, error) {
+	var report SelfTestReport
+
+	d.config.mode = ModeForced
+	d.config.HeatrProfile = nil
+	d.config.HeatrCycle = false
+	d.config.Humidity = Sampling1X
+	d.config.Pressure = Sampling16X
+	d.config.Temperature = Sampling2X
+
+	if err := d.applyConfig(context.Background()); err != nil {
+		return report, fmt.Errorf("failed to apply config: %w", err)
+	}
+
+	if err := d.SetGasHeater(selfTestHighTemp, selfTestHeatrDur1, true); err != nil {
+		return report, err
+	}
+
+	m, err := d.Measure()
+	if err != nil {
+		return report, err
+	}
+
+	// the heater current DAC saturates when the heater is open or shorted
+	heater := m.Idac != 0x00 && m.Idac != 0xFF && m.GasValid()
+	report.Heater = newSelfTestCheck("heater", boolToFloat(heater), 1, 1)
+
+	for i := range report.Measurements {
+		temp := selfTestLowTemp
+		if i%2 != 0 {
+			temp = selfTestHighTemp
+		}
+
+		if err := d.SetGasHeater(temp, selfTestHeatrDur2, true); err != nil {
+			return report, err
+		}
+
+		if report.Measurements[i], err = d.Measure(); err != nil {
+			return report, err
+		}
+	}
+
+	// the integer fields are set by both compensations
+	first := report.Measurements[0]
+	report.Temperature = newSelfTestCheck("temperature", float32(first.TemperatureMilli)/1000, selfTestMinTemperature, selfTestMaxTemperature)
+	report.Pressure = newSelfTestCheck("pressure", float32(first.PressurePa), selfTestMinPressure, selfTestMaxPressure)
+	report.Humidity = newSelfTestCheck("humidity", float32(first.HumidityMilli)/1000, selfTestMinHumidity, selfTestMaxHumidity)
+
+	var valid float32
+	for _, m := range report.Measurements {
+		valid += boolToFloat(m.GasValid())
+	}
+	report.GasValid = newSelfTestCheck("gas valid", valid, SelfTestMeasurements, SelfTestMeasurements)
+
+	var ratio float32
+	if low := float32(report.Measurements[4].GasResistanceOhms); low != 0 {
+		ratio = float32(report.Measurements[3].GasResistanceOhms+report.Measurements[5].GasResistanceOhms) / (2 * low)
+	}
+	report.GasRatio = newSelfTestCheck("gas ratio", ratio, selfTestMinGasRatio, float32(math.Inf(1)))
+
+	return report, nil
+}
+
+// Checks returns every check of the report.
+func (r SelfTestReport) Checks() []SelfTestCheck {
+	return []SelfTestCheck{r.Heater, r.Temperature, r.Pressure, r.Humidity, r.GasValid, r.GasRatio}
+}
+
+// Passed reports whether every check passed.
+func (r SelfTestReport) Passed() bool {
+	for _, c := range r.Checks() {
+		if !c.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String implements fmt.Stringer interface.
+func (r SelfTestReport) String() string {
+	var sb strings.Builder
+
+	for _, c := range r.Checks() {
+		sb.WriteString(c.String())
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// String implements fmt.Stringer interface.
+func (c SelfTestCheck) String() string {
+	result := "FAIL"
+	if c.Passed {
+		result = "PASS"
+	}
+
+	return fmt.Sprintf("%s: %s (value: %.2f, min: %.2f, max: %.2f)", c.Name, result, c.Value, c.Min, c.Max)
+}
+
+func newSelfTestCheck(name string, value, min, max float32) SelfTestCheck {
+	return SelfTestCheck{
+		Name:   name,
+		Passed: value >= min && value <= max,
+		Value:  value,
+		Min:    min,
+		Max:    max,
+	}
+}
+
+func boolToFloat(b bool) float32 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,116 @@
+package bme68x_test
+
+import (
+	"sync"
+	"testing"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/emulator"
+)
+
+// heaterI2C switches the gas ADC of the emulator on each write of the heater
+// target, to the hot ADC for the highest target written so far, the 350°C of
+// the self-test being written first.
+type heaterI2C struct {
+	*emulator.I2C
+	device    *emulator.Device
+	cold, hot emulator.ADC
+	max       uint8
+}
+
+func (b *heaterI2C) Tx(addr uint16, w, r []byte) error {
+	for i := 0; i+1 < len(w); i += 2 {
+		if w[i] != bme68x.REG_RES_HEAT0 {
+			continue
+		}
+
+		b.max = max(b.max, w[i+1])
+
+		if w[i+1] == b.max {
+			b.device.SetADC(b.hot)
+		} else {
+			b.device.SetADC(b.cold)
+		}
+	}
+
+	return b.I2C.Tx(addr, w, r)
+}
+
+func TestSelfTest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the self-test takes about 13s")
+	}
+
+	adc := func(gas uint16) emulator.ADC {
+		return emulator.ADC{Temperature: 500000, Pressure: 350000, Humidity: 20000, Gas: gas, GasRange: 5}
+	}
+
+	cases := []struct {
+		name string
+		idac uint8
+		// gas ADC at 350°C and 150°C, the resistance falling as the ADC
+		// value rises
+		hot, cold uint16
+		failed    []string
+	}{
+		{"passed", 0x42, 300, 600, nil},
+		{"low ratio", 0x42, 600, 600, []string{"gas ratio"}},
+		{"shorted heater", 0xFF, 300, 600, []string{"heater"}},
+	}
+
+	// the self-tests sleep in parallel
+	var (
+		wg      sync.WaitGroup
+		reports = make([]bme68x.SelfTestReport, len(cases))
+		errs    = make([]error, len(cases))
+	)
+
+	for i, c := range cases {
+		wg.Go(func() {
+			e := emulator.New(emulator.WithIdac(c.idac))
+			d := bme68x.NewI2C(&heaterI2C{I2C: e.I2C(), device: e, cold: adc(c.cold), hot: adc(c.hot)})
+
+			if errs[i] = d.Configure(); errs[i] == nil {
+				reports[i], errs[i] = d.SelfTest()
+			}
+		})
+	}
+
+	wg.Wait()
+
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			report, err := reports[i], errs[i]
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var failed []string
+			for _, check := range report.Checks() {
+				if !check.Passed {
+					failed = append(failed, check.Name)
+				}
+			}
+
+			if len(failed) != len(c.failed) || (len(failed) > 0 && failed[0] != c.failed[0]) {
+				t.Errorf("failed checks %q, want %q:\n%v", failed, c.failed, report)
+			}
+
+			if report.Passed() != (len(c.failed) == 0) {
+				t.Errorf("passed %v, want %v", report.Passed(), len(c.failed) == 0)
+			}
+
+			// the 150°C measurements alternate with the 350°C ones
+			ms := report.Measurements
+			for i := 2; i < len(ms); i++ {
+				if ms[i].GasResistanceOhms != ms[i%2].GasResistanceOhms {
+					t.Errorf("measurement %d: gas resistance %v, want %v", i, ms[i].GasResistanceOhms, ms[i%2].GasResistanceOhms)
+				}
+			}
+
+			if (ms[0].GasResistanceOhms == ms[1].GasResistanceOhms) != (c.cold == c.hot) {
+				t.Errorf("gas resistance %v at 150°C and %v at 350°C", ms[0].GasResistanceOhms, ms[1].GasResistanceOhms)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package bme68x
+
+import (
+	"tinygo.org/x/drivers"
+)
+
+var _ drivers.Sensor = (*Device)(nil)
+
+// Update implements drivers.Sensor interface. A single measurement provides
+// the temperature, pressure, humidity and gas resistance, so any of them
+// triggers it. The values are then available from Temperature, Pressure,
+// Humidity and GasResistance.
+func (d *Device) Update(which drivers.Measurement) error {
+	if which&(drivers.Temperature|drivers.Pressure|drivers.Humidity|drivers.Concentration) == 0 {
+		return nil
+	}
+
+	return d.Read()
+}
+
+// Temperature returns the last temperature read by Update, in milli degree
+// Celsius.
+func (d *Device) Temperature() (int32, error) {
+	if !d.last.NewData() {
+		return 0, ErrNoNewData
+	}
+
+	return d.last.TemperatureMilli, nil
+}
+
+// Pressure returns the last pressure read by Update, in milli Pascal.
+func (d *Device) Pressure() (int32, error) {
+	if !d.last.NewData() {
+		return 0, ErrNoNewData
+	}
+
+	return int32(d.last.PressurePa) * 1000, nil
+}
+
+// Humidity returns the last relative humidity read by Update, in hundredths
+// of a percent.
+func (d *Device) Humidity() (int32, error) {
+	if !d.last.NewData() {
+		return 0, ErrNoNewData
+	}
+
+	return int32(d.last.HumidityMilli) / 10, nil
+}
+
+// GasResistance returns the last gas resistance read by Update, in Ohms. It
+// returns ErrGasInvalid or ErrHeaterUnstable when the gas measurement can not
+// be trusted.
+func (d *Device) GasResistance() (uint32, error) {
+	if !d.last.NewData() {
+		return 0, ErrNoNewData
+	}
+
+	if err := d.last.GasErr(); err != nil {
+		return 0, err
+	}
+
+	return d.last.GasResistanceOhms, nil
+}
+
+// LastMeasurement returns the last measurement read by Read or Update.
+func (d *Device) LastMeasurement() Measurement {
+	return d.last
+}
@@ -0,0 +1,91 @@
+package bme68x_test
+
+import (
+	"errors"
+	"testing"
+
+	"tinygo.org/x/drivers"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/emulator"
+)
+
+func TestSensor(t *testing.T) {
+	for _, bus := range emulator.Buses {
+		t.Run(bus.Name, func(t *testing.T) {
+			d := configured(t, bus)
+
+			if _, err := d.Temperature(); !errors.Is(err, bme68x.ErrNoNewData) {
+				t.Errorf("Temperature before Update returned %v, want %v", err, bme68x.ErrNoNewData)
+			}
+
+			// none of the measurements of the sensor
+			if err := d.Update(drivers.Voltage | drivers.Distance); err != nil {
+				t.Fatal(err)
+			}
+
+			if m := d.LastMeasurement(); !m.Time.IsZero() {
+				t.Errorf("Update without measurement of the sensor read %v", m)
+			}
+
+			for _, which := range []drivers.Measurement{drivers.Pressure, drivers.Concentration, drivers.AllMeasurements} {
+				before := d.LastMeasurement()
+
+				if err := d.Update(which); err != nil {
+					t.Fatal(err)
+				}
+
+				m := d.LastMeasurement()
+				if !m.NewData() || m.Time.Equal(before.Time) && m.MeasIndex == before.MeasIndex {
+					t.Fatalf("Update(%d) did not read a new measurement: %v", which, m)
+				}
+
+				temp, err := d.Temperature()
+				if err != nil || temp != m.TemperatureMilli {
+					t.Errorf("temperature %d, %v, want %dm°C", temp, err, m.TemperatureMilli)
+				}
+
+				pres, err := d.Pressure()
+				if err != nil || pres != int32(m.PressurePa)*1000 {
+					t.Errorf("pressure %d, %v, want %dmPa", pres, err, m.PressurePa*1000)
+				}
+
+				hum, err := d.Humidity()
+				if err != nil || hum != int32(m.HumidityMilli/10) {
+					t.Errorf("humidity %d, %v, want %d centi percent", hum, err, m.HumidityMilli/10)
+				}
+
+				gas, err := d.GasResistance()
+				if err != nil || gas != m.GasResistanceOhms || gas == 0 {
+					t.Errorf("gas resistance %d, %v, want %dΩ", gas, err, m.GasResistanceOhms)
+				}
+			}
+		})
+	}
+}
+
+func TestSensorGasInvalid(t *testing.T) {
+	e := emulator.New()
+	d := bme68x.NewI2C(e.I2C(), bme68x.WithHeatrDuration(2))
+
+	if err := d.Configure(); err != nil {
+		t.Fatal(err)
+	}
+
+	// without heater, the gas measurement is not run
+	if err := d.SetGasHeater(0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Update(drivers.Temperature); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Temperature(); err != nil {
+		t.Errorf("Temperature returned %v", err)
+	}
+
+	if _, err := d.GasResistance(); !errors.Is(err, bme68x.ErrGasInvalid) {
+		t.Errorf("GasResistance without heater returned %v, want %v", err, bme68x.ErrGasInvalid)
+	}
+}
@@ -1,6 +1,7 @@
 package bme68x
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -22,24 +23,48 @@ const (
 	MEM_PAGE1 uint8 = 0x00
 )
 
+// Pin is the chip select pin of the SPI bus, driven low for the duration of
+// each transaction. machine.Pin implements it once configured as an output.
+type Pin interface {
+	High()
+	Low()
+}
+
 type spi struct {
 	bus drivers.SPI
+	cs  Pin
 	// memoryPage is the current memory page
 	memoryPage uint8
+	// cmd holds the register address of a read or the memory page write
+	cmd [2]byte
+	// page holds the memory page register read
+	page [1]byte
+	// buf holds the interleaved register/value pairs of a write
+	buf [LEN_INTERLEAVE_BUFF]byte
 }
 
-// Reset performs a soft reset of the BME68x sensor.
-func (s *spi) Reset(_ uint16) error {
+// Reset performs a soft reset of the BME68x sensor, the reset period being
+// cut short when the context is done.
+func (s *spi) Reset(ctx context.Context, _ uint16) error {
 	if err := s.readMemoryPage(); err != nil {
 		return fmt.Errorf("failed to read memory page: %w", err)
 	}
 
-	if err := s.Write(0, []uint8{REG_SOFT_RESET}, []byte{CMD_RESET}); err != nil {
+	if err := s.setMemoryPage(REG_SOFT_RESET); err != nil {
+		return fmt.Errorf("failed to set memory page: %w", err)
+	}
+
+	s.cmd[0] = REG_SOFT_RESET & SPI_WR_MSK
+	s.cmd[1] = CMD_RESET
+
+	if err := s.tx(s.cmd[:2], nil); err != nil {
 		return fmt.Errorf("failed to soft reset command: %w", err)
 	}
 
 	// wait for 10ms
-	time.Sleep(time.Duration(PeriodReset) * time.Microsecond)
+	if err := sleep(ctx, time.Duration(PeriodReset)*time.Microsecond); err != nil {
+		return err
+	}
 
 	// after reset get the memory page
 	if err := s.readMemoryPage(); err != nil {
@@ -59,64 +84,96 @@ func (s *spi) Read(_ uint16, reg uint8, data []byte) error {
 }
 
 func (s *spi) read(reg uint8, data []byte) error {
-	reg |= SPI_RD_MSK
+	s.cmd[0] = reg | SPI_RD_MSK
 
-	return s.bus.Tx([]byte{reg}, data)
+	return s.tx(s.cmd[:1], data)
 }
 
-// Write writes data to the BME68x sensor over SPI.
+// Write writes data to the BME68x sensor over SPI. The register/value pairs
+// are interleaved in burst writes of at most LEN_INTERLEAVE_BUFF bytes, and a
+// new burst starts when the memory page changes.
 func (s *spi) Write(_ uint16, reg []uint8, data []byte) error {
-	buf := make([]uint8, LEN_INTERLEAVE_BUFF)
+	if len(reg) != len(data) {
+		return fmt.Errorf("%d registers for %d values", len(reg), len(data))
+	}
+
+	n := 0
 
-	if len(data) > 0 && len(data) <= int(LEN_INTERLEAVE_BUFF/2) {
-		for i := 0; i < len(data); i++ {
-			if err := s.setMemoryPage(reg[i]); err != nil {
-				return fmt.Errorf("failed to set memory page: %w", err)
+	for i := range data {
+		// flush the pairs written in another memory page or filling the buffer
+		if n > 0 && (memoryPageOf(reg[i]) != s.memoryPage || n == int(LEN_INTERLEAVE_BUFF/2)) {
+			if err := s.tx(s.buf[:2*n], nil); err != nil {
+				return err
 			}
 
-			buf[2*i] = reg[i] & SPI_WR_MSK
-			buf[2*i+1] = data[i]
+			n = 0
 		}
 
-		if err := s.bus.Tx(buf, nil); err != nil {
-			return err
+		if err := s.setMemoryPage(reg[i]); err != nil {
+			return fmt.Errorf("failed to set memory page: %w", err)
 		}
+
+		s.buf[2*n] = reg[i] & SPI_WR_MSK
+		s.buf[2*n+1] = data[i]
+		n++
+	}
+
+	if n > 0 {
+		return s.tx(s.buf[:2*n], nil)
 	}
 
 	return nil
 }
 
-func (s *spi) setMemoryPage(reg uint8) error {
-	memoryPage := MEM_PAGE0
-
+// memoryPageOf returns the memory page holding the register.
+func memoryPageOf(reg uint8) uint8 {
 	if reg > 0x7F {
-		memoryPage = MEM_PAGE1
+		return MEM_PAGE1
 	}
 
+	return MEM_PAGE0
+}
+
+func (s *spi) setMemoryPage(reg uint8) error {
+	memoryPage := memoryPageOf(reg)
+
 	if memoryPage == s.memoryPage {
 		return nil
 	}
 
 	s.memoryPage = memoryPage
 
-	var data [1]byte
-	if err := s.read(REG_MEM_PAGE|SPI_RD_MSK, data[:]); err != nil {
+	if err := s.read(REG_MEM_PAGE|SPI_RD_MSK, s.page[:]); err != nil {
 		return fmt.Errorf("failed to read memory page: %w", err)
 	}
 
-	data[0] &^= MEM_PAGE_MSK
-	data[0] |= (memoryPage & MEM_PAGE_MSK)
+	s.cmd[0] = REG_MEM_PAGE & SPI_WR_MSK
+	s.cmd[1] = (s.page[0] &^ MEM_PAGE_MSK) | (memoryPage & MEM_PAGE_MSK)
+
+	return s.tx(s.cmd[:2], nil)
+}
+
+// tx performs an SPI transaction with the chip select asserted: w is written,
+// then r is read. The bytes received while writing w are discarded.
+func (s *spi) tx(w, r []byte) error {
+	s.cs.Low()
+
+	err := s.bus.Tx(w, nil)
+	if err == nil && len(r) > 0 {
+		err = s.bus.Tx(nil, r)
+	}
+
+	s.cs.High()
 
-	return s.bus.Tx([]byte{REG_MEM_PAGE | SPI_WR_MSK}, []byte{data[0]})
+	return err
 }
 
 func (s *spi) readMemoryPage() error {
-	var reg [1]byte
-	if err := s.read(REG_MEM_PAGE|SPI_RD_MSK, reg[:]); err != nil {
+	if err := s.read(REG_MEM_PAGE|SPI_RD_MSK, s.page[:]); err != nil {
 		return err
 	}
 
-	s.memoryPage = reg[0] & MEM_PAGE_MSK
+	s.memoryPage = s.page[0] & MEM_PAGE_MSK
 
 	return nil
 }
@@ -0,0 +1,180 @@
+package bme68x
+
+import (
+	"fmt"
+	"testing"
+)
+
+// event is a chip select edge, "low" or "high", or an SPI transaction.
+type event string
+
+// fakePin records its edges in a shared event log.
+type fakePin struct {
+	events *[]event
+}
+
+func (p fakePin) High() { *p.events = append(*p.events, "high") }
+func (p fakePin) Low()  { *p.events = append(*p.events, "low") }
+
+// recordingSPI records its transactions in a shared event log. The memory
+// page register reads as page 1, the other registers as 0.
+type recordingSPI struct {
+	events *[]event
+}
+
+func (b *recordingSPI) Tx(w, r []byte) error {
+	*b.events = append(*b.events, event(fmt.Sprintf("tx % X/%d", w, len(r))))
+
+	for i := range r {
+		r[i] = 0
+	}
+
+	return nil
+}
+
+func (b *recordingSPI) Transfer(w byte) (byte, error) {
+	return 0, fmt.Errorf("unexpected Transfer(0x%02X)", w)
+}
+
+// transactions checks that the chip select is low during each transaction
+// and high at the end, and returns the number of selections.
+func transactions(t *testing.T, events []event) int {
+	t.Helper()
+
+	selections, low := 0, false
+
+	for i, e := range events {
+		switch e {
+		case "low":
+			if low {
+				t.Errorf("event %d: chip select already low: %q", i, events)
+			}
+
+			selections++
+			low = true
+		case "high":
+			low = false
+		default:
+			if !low {
+				t.Errorf("event %d: %s with chip select high: %q", i, e, events)
+			}
+		}
+	}
+
+	if low {
+		t.Errorf("chip select left low: %q", events)
+	}
+
+	return selections
+}
+
+func TestSPIChipSelect(t *testing.T) {
+	var events []event
+
+	bus := &recordingSPI{events: &events}
+	d := NewSPI(bus, fakePin{events: &events})
+
+	if len(events) != 1 || events[0] != "high" {
+		t.Fatalf("NewSPI events %q, want the chip select driven high", events)
+	}
+
+	for _, c := range []struct {
+		name       string
+		do         func() error
+		selections int
+	}{
+		// page 1 is selected, a single transaction
+		{"read page 1", func() error { return d.bus.Read(0, REG_CHIP_ID, make([]byte, 1)) }, 1},
+		// the memory page is read and written before the read
+		{"read page 0", func() error { return d.bus.Read(0, REG_CTRL_MEAS, make([]byte, 1)) }, 3},
+		{"write page 0", func() error { return d.bus.Write(0, []uint8{REG_CTRL_HUM, REG_CTRL_MEAS}, []byte{1, 2}) }, 1},
+		// the memory page is switched back, then a single burst
+		{"write page 1", func() error { return d.bus.Write(0, []uint8{REG_SOFT_RESET}, []byte{CMD_RESET}) }, 3},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			events = events[:0]
+
+			if err := c.do(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := transactions(t, events); got != c.selections {
+				t.Errorf("%d chip selections, want %d: %q", got, c.selections, events)
+			}
+		})
+	}
+}
+
+func TestSPIWriteBursts(t *testing.T) {
+	var events []event
+
+	d := NewSPI(&recordingSPI{events: &events}, fakePin{events: &events})
+
+	// more pairs than a burst holds in page 0, then a few in page 1
+	page0 := int(LEN_INTERLEAVE_BUFF/2) + 2
+	page1 := 3
+
+	var reg []uint8
+	var data []byte
+
+	for i := 0; i < page0; i++ {
+		reg = append(reg, 0x50+uint8(i))
+		data = append(data, uint8(i))
+	}
+
+	for i := 0; i < page1; i++ {
+		reg = append(reg, REG_COEFF1+uint8(i))
+		data = append(data, 0x80+uint8(i))
+	}
+
+	events = events[:0]
+
+	if err := d.bus.Write(0, reg, data); err != nil {
+		t.Fatal(err)
+	}
+
+	// burst returns the transaction writing the pairs of registers [from, to)
+	burst := func(from, to int) event {
+		var w []byte
+
+		for i := from; i < to; i++ {
+			w = append(w, reg[i]&SPI_WR_MSK, data[i])
+		}
+
+		return event(fmt.Sprintf("tx % X/0", w))
+	}
+
+	// switchPage returns the transactions reading then writing the memory page
+	switchPage := func(page uint8) []event {
+		return []event{
+			event(fmt.Sprintf("tx % X/0", []byte{REG_MEM_PAGE | SPI_RD_MSK})),
+			"tx /1",
+			event(fmt.Sprintf("tx % X/0", []byte{REG_MEM_PAGE & SPI_WR_MSK, page})),
+		}
+	}
+
+	var want []event
+
+	want = append(want, switchPage(MEM_PAGE0)...)
+	// the full buffer is flushed, then the rest of page 0 at the page change
+	want = append(want, burst(0, int(LEN_INTERLEAVE_BUFF/2)), burst(int(LEN_INTERLEAVE_BUFF/2), page0))
+	want = append(want, switchPage(MEM_PAGE1)...)
+	want = append(want, burst(page0, page0+page1))
+
+	// 2 selections for each memory page switch, 3 bursts
+	if got := transactions(t, events); got != 7 {
+		t.Errorf("%d chip selections, want 7: %q", got, events)
+	}
+
+	var got []event
+
+	for _, e := range events {
+		if e != "low" && e != "high" {
+			got = append(got, e)
+		}
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("transactions\n%q\nwant\n%q", got, want)
+	}
+}
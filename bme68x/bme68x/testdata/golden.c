@@ -0,0 +1,353 @@
+// golden.c generates golden.json, the golden vectors of the compensation
+// formulas. The formulas are those of the Bosch BME68x SensorAPI
+// (https://github.com/boschsensortec/BME68x_SensorAPI), both the floating
+// point (BME68X_USE_FPU) and the integer variants, with the var3 term of the
+// integer pressure widened to 64 bits as in compensation_int.go.
+//
+//	gcc -O0 -o golden golden.c && ./golden > golden.json
+#include <stdint.h>
+#include <stdio.h>
+
+struct calib {
+	uint16_t par_t1; int16_t par_t2; int8_t par_t3;
+	uint16_t par_p1; int16_t par_p2; int8_t par_p3; int16_t par_p4; int16_t par_p5;
+	int8_t par_p6; int8_t par_p7; int16_t par_p8; int16_t par_p9; uint8_t par_p10;
+	uint16_t par_h1; uint16_t par_h2; int8_t par_h3; int8_t par_h4; int8_t par_h5; uint8_t par_h6; int8_t par_h7;
+	int8_t par_gh1; int16_t par_gh2; int8_t par_gh3;
+	uint8_t res_heat_range; int8_t res_heat_val; int8_t range_sw_err;
+	float t_fine; int32_t t_fine_int;
+	int8_t amb_temp;
+};
+
+static const uint8_t images[][42] = {
+	{
+		0x2B, 0x67, 0x03, 0x00, 0x14, 0x8E, 0x0B, 0xD7, 0x58, 0x00, 0xFB, 0x1C,
+		0x78, 0xFF, 0x2C, 0x1E, 0x00, 0x00, 0x92, 0xF4, 0xC8, 0xF7, 0x1E,
+		0x40, 0x39, 0x33, 0x00, 0x2D, 0x14, 0x78, 0x9C, 0x79, 0x66, 0x80, 0xD8,
+		0xCE, 0x12,
+		0x28, 0x00, 0x10, 0x00, 0xF0,
+	},
+	{
+		0x6F, 0x66, 0x03, 0x10, 0x52, 0x90, 0x7D, 0xD6, 0x58, 0x00, 0x3E, 0x22,
+		0x64, 0xFF, 0x1E, 0x1E, 0x00, 0x00, 0x8E, 0xF7, 0x3C, 0xFF, 0x1E,
+		0x3E, 0xC3, 0x33, 0x00, 0x2D, 0x14, 0x78, 0x9C, 0x3A, 0x65, 0x4D, 0xEB,
+		0xEB, 0x12,
+		0x31, 0x00, 0x15, 0x00, 0x10,
+	},
+	{
+		0x10, 0x69, 0x02, 0x00, 0xC2, 0x8B, 0x2E, 0xD5, 0x5A, 0x00, 0x64, 0x1A,
+		0x9C, 0xFF, 0x27, 0x1E, 0x00, 0x00, 0xE6, 0xF5, 0x6B, 0xF8, 0x1E,
+		0x3F, 0x55, 0x34, 0x00, 0x2D, 0x14, 0x78, 0x9C, 0x9A, 0x67, 0x66, 0xE2,
+		0xB8, 0x12,
+		0x1E, 0x00, 0x20, 0x00, 0xE0,
+	},
+	{
+		/* par_p10 >= 0x80, unsigned in the SensorAPI */
+		0x2B, 0x67, 0x03, 0x00, 0x14, 0x8E, 0x0B, 0xD7, 0x58, 0x00, 0xFB, 0x1C,
+		0x78, 0xFF, 0x2C, 0x1E, 0x00, 0x00, 0x92, 0xF4, 0xC8, 0xF7, 0xC8,
+		0x40, 0x39, 0x33, 0x00, 0x2D, 0x14, 0x78, 0x9C, 0x79, 0x66, 0x80, 0xD8,
+		0xCE, 0x12,
+		0x28, 0x00, 0x10, 0x00, 0xF0,
+	},
+};
+
+static void parse(const uint8_t *c, struct calib *cal)
+{
+	cal->par_t1 = (uint16_t)((c[32] << 8) | c[31]);
+	cal->par_t2 = (int16_t)((c[1] << 8) | c[0]);
+	cal->par_t3 = (int8_t)c[2];
+	cal->par_p1 = (uint16_t)((c[5] << 8) | c[4]);
+	cal->par_p2 = (int16_t)((c[7] << 8) | c[6]);
+	cal->par_p3 = (int8_t)c[8];
+	cal->par_p4 = (int16_t)((c[11] << 8) | c[10]);
+	cal->par_p5 = (int16_t)((c[13] << 8) | c[12]);
+	cal->par_p6 = (int8_t)c[15];
+	cal->par_p7 = (int8_t)c[14];
+	cal->par_p8 = (int16_t)((c[19] << 8) | c[18]);
+	cal->par_p9 = (int16_t)((c[21] << 8) | c[20]);
+	cal->par_p10 = c[22];
+	cal->par_h1 = (uint16_t)((c[25] << 4) | (c[24] & 0x0F));
+	cal->par_h2 = (uint16_t)((c[23] << 4) | (c[24] >> 4));
+	cal->par_h3 = (int8_t)c[26];
+	cal->par_h4 = (int8_t)c[27];
+	cal->par_h5 = (int8_t)c[28];
+	cal->par_h6 = c[29];
+	cal->par_h7 = (int8_t)c[30];
+	cal->par_gh1 = (int8_t)c[35];
+	cal->par_gh2 = (int16_t)((c[34] << 8) | c[33]);
+	cal->par_gh3 = (int8_t)c[36];
+	cal->res_heat_range = (c[39] & 0x30) / 16;
+	cal->res_heat_val = (int8_t)c[37];
+	cal->range_sw_err = ((int8_t)c[41] & (int8_t)0xF0) / 16;
+}
+
+static float calc_temperature(uint32_t temp_adc, struct calib *cal)
+{
+	float var1, var2;
+	var1 = ((((float)temp_adc / 16384.0f) - ((float)cal->par_t1 / 1024.0f)) * ((float)cal->par_t2));
+	var2 = (((((float)temp_adc / 131072.0f) - ((float)cal->par_t1 / 8192.0f)) *
+		 (((float)temp_adc / 131072.0f) - ((float)cal->par_t1 / 8192.0f))) * ((float)cal->par_t3 * 16.0f));
+	cal->t_fine = (var1 + var2);
+	return cal->t_fine / 5120.0f;
+}
+
+static float calc_pressure(uint32_t pres_adc, const struct calib *cal)
+{
+	float var1, var2, var3, calc_pres;
+	var1 = (((float)cal->t_fine / 2.0f) - 64000.0f);
+	var2 = var1 * var1 * (((float)cal->par_p6) / (131072.0f));
+	var2 = var2 + (var1 * ((float)cal->par_p5) * 2.0f);
+	var2 = (var2 / 4.0f) + (((float)cal->par_p4) * 65536.0f);
+	var1 = (((((float)cal->par_p3 * var1 * var1) / 16384.0f) + ((float)cal->par_p2 * var1)) / 524288.0f);
+	var1 = ((1.0f + (var1 / 32768.0f)) * ((float)cal->par_p1));
+	calc_pres = (1048576.0f - ((float)pres_adc));
+	if ((int)var1 == 0)
+		return 0;
+	calc_pres = (((calc_pres - (var2 / 4096.0f)) * 6250.0f) / var1);
+	var1 = (((float)cal->par_p9) * calc_pres * calc_pres) / 2147483648.0f;
+	var2 = calc_pres * (((float)cal->par_p8) / 32768.0f);
+	var3 = ((calc_pres / 256.0f) * (calc_pres / 256.0f) * (calc_pres / 256.0f) * (cal->par_p10 / 131072.0f));
+	return (calc_pres + (var1 + var2 + var3 + ((float)cal->par_p7 * 128.0f)) / 16.0f);
+}
+
+static float calc_humidity(uint16_t hum_adc, const struct calib *cal)
+{
+	float calc_hum, var1, var2, var3, var4, temp_comp;
+	temp_comp = ((cal->t_fine) / 5120.0f);
+	var1 = (float)((float)hum_adc) - (((float)cal->par_h1 * 16.0f) + (((float)cal->par_h3 / 2.0f) * temp_comp));
+	var2 = var1 * ((float)(((float)cal->par_h2 / 262144.0f) *
+		(1.0f + (((float)cal->par_h4 / 16384.0f) * temp_comp) +
+		 (((float)cal->par_h5 / 1048576.0f) * temp_comp * temp_comp))));
+	var3 = (float)cal->par_h6 / 16384.0f;
+	var4 = (float)cal->par_h7 / 2097152.0f;
+	calc_hum = var2 + ((var3 + (var4 * temp_comp)) * var2 * var2);
+	if (calc_hum > 100.0f)
+		calc_hum = 100.0f;
+	else if (calc_hum < 0.0f)
+		calc_hum = 0.0f;
+	return calc_hum;
+}
+
+static float calc_gas_resistance_low(uint16_t gas_res_adc, uint8_t gas_range, const struct calib *cal)
+{
+	float var1, var2, var3;
+	float gas_res_f = gas_res_adc;
+	float gas_range_f = (1U << gas_range);
+	const float lookup_k1_range[16] = {
+		0.0f, 0.0f, 0.0f, 0.0f, 0.0f, -1.0f, 0.0f, -0.8f, 0.0f, 0.0f, -0.2f, -0.5f, 0.0f, -1.0f, 0.0f, 0.0f
+	};
+	const float lookup_k2_range[16] = {
+		0.0f, 0.0f, 0.0f, 0.0f, 0.1f, 0.7f, 0.0f, -0.8f, -0.1f, 0.0f, 0.0f, 0.0f, 0.0f, 0.0f, 0.0f, 0.0f
+	};
+	var1 = (1340.0f + (5.0f * cal->range_sw_err));
+	var2 = (var1) * (1.0f + lookup_k1_range[gas_range] / 100.0f);
+	var3 = 1.0f + (lookup_k2_range[gas_range] / 100.0f);
+	return 1.0f / (float)(var3 * (0.000000125f) * gas_range_f * (((gas_res_f - 512.0f) / var2) + 1.0f));
+}
+
+static float calc_gas_resistance_high(uint16_t gas_res_adc, uint8_t gas_range)
+{
+	uint32_t var1 = UINT32_C(262144) >> gas_range;
+	int32_t var2 = (int32_t)gas_res_adc - INT32_C(512);
+	var2 *= INT32_C(3);
+	var2 = INT32_C(4096) + var2;
+	return 1000000.0f * (float)var1 / (float)var2;
+}
+
+static uint8_t calc_res_heat(uint16_t temp, const struct calib *cal)
+{
+	float var1, var2, var3, var4, var5;
+	if (temp > 400)
+		temp = 400;
+	var1 = (((float)cal->par_gh1 / (16.0f)) + 49.0f);
+	var2 = ((((float)cal->par_gh2 / (32768.0f)) * (0.0005f)) + 0.00235f);
+	var3 = ((float)cal->par_gh3 / (1024.0f));
+	var4 = (var1 * (1.0f + (var2 * (float)temp)));
+	var5 = (var4 + (var3 * (float)cal->amb_temp));
+	return (uint8_t)(3.4f * ((var5 * (4 / (4 + (float)cal->res_heat_range)) *
+		(1 / (1 + ((float)cal->res_heat_val * 0.002f)))) - 25));
+}
+
+static int16_t calc_temperature_int(uint32_t temp_adc, struct calib *cal)
+{
+	int64_t var1, var2, var3;
+	var1 = ((int32_t)temp_adc >> 3) - ((int32_t)cal->par_t1 << 1);
+	var2 = (var1 * (int32_t)cal->par_t2) >> 11;
+	var3 = ((var1 >> 1) * (var1 >> 1)) >> 12;
+	var3 = ((var3) * ((int32_t)cal->par_t3 << 4)) >> 14;
+	cal->t_fine_int = (int32_t)(var2 + var3);
+	return (int16_t)(((cal->t_fine_int * 5) + 128) >> 8);
+}
+
+static uint32_t calc_pressure_int(uint32_t pres_adc, const struct calib *cal)
+{
+	int32_t var1, var2, var3, pressure_comp;
+	const int32_t pres_ovf_check = INT32_C(0x40000000);
+	var1 = (((int32_t)cal->t_fine_int) >> 1) - 64000;
+	var2 = ((((var1 >> 2) * (var1 >> 2)) >> 11) * (int32_t)cal->par_p6) >> 2;
+	var2 = var2 + ((var1 * (int32_t)cal->par_p5) << 1);
+	var2 = (var2 >> 2) + ((int32_t)cal->par_p4 << 16);
+	var1 = (((((var1 >> 2) * (var1 >> 2)) >> 13) * ((int32_t)cal->par_p3 << 5)) >> 3) +
+	       (((int32_t)cal->par_p2 * var1) >> 1);
+	var1 = var1 >> 18;
+	var1 = ((32768 + var1) * (int32_t)cal->par_p1) >> 15;
+	pressure_comp = 1048576 - pres_adc;
+	pressure_comp = (int32_t)((pressure_comp - (var2 >> 12)) * ((uint32_t)3125));
+	if (pressure_comp >= pres_ovf_check)
+		pressure_comp = ((pressure_comp / var1) << 1);
+	else
+		pressure_comp = ((pressure_comp << 1) / var1);
+	var1 = ((int32_t)cal->par_p9 * (int32_t)(((pressure_comp >> 3) * (pressure_comp >> 3)) >> 13)) >> 12;
+	var2 = ((int32_t)(pressure_comp >> 2) * (int32_t)cal->par_p8) >> 13;
+	/* widened to 64 bits, the SensorAPI overflows above about 100 kPa */
+	var3 = (int32_t)(((int64_t)(pressure_comp >> 8) * (int64_t)(pressure_comp >> 8) * (int64_t)(pressure_comp >> 8) *
+		(int64_t)cal->par_p10) >> 17);
+	pressure_comp = (int32_t)(pressure_comp) + ((var1 + var2 + var3 + ((int32_t)cal->par_p7 << 7)) >> 4);
+	return (uint32_t)pressure_comp;
+}
+
+static uint32_t calc_humidity_int(uint16_t hum_adc, const struct calib *cal)
+{
+	int32_t var1, var2, var3, var4, var5, var6, temp_scaled, calc_hum;
+	temp_scaled = (((int32_t)cal->t_fine_int * 5) + 128) >> 8;
+	var1 = (int32_t)(hum_adc - ((int32_t)((int32_t)cal->par_h1 * 16))) -
+	       (((temp_scaled * (int32_t)cal->par_h3) / ((int32_t)100)) >> 1);
+	var2 = ((int32_t)cal->par_h2 *
+		(((temp_scaled * (int32_t)cal->par_h4) / ((int32_t)100)) +
+		 (((temp_scaled * ((temp_scaled * (int32_t)cal->par_h5) / ((int32_t)100))) >> 6) / ((int32_t)100)) +
+		 (int32_t)(1 << 14))) >> 10;
+	var3 = var1 * var2;
+	var4 = (int32_t)cal->par_h6 << 7;
+	var4 = ((var4) + ((temp_scaled * (int32_t)cal->par_h7) / ((int32_t)100))) >> 4;
+	var5 = ((var3 >> 14) * (var3 >> 14)) >> 10;
+	var6 = (var4 * var5) >> 1;
+	calc_hum = (((var3 + var6) >> 10) * ((int32_t)1000)) >> 12;
+	if (calc_hum > 100000)
+		calc_hum = 100000;
+	else if (calc_hum < 0)
+		calc_hum = 0;
+	return (uint32_t)calc_hum;
+}
+
+static uint32_t calc_gas_resistance_low_int(uint16_t gas_res_adc, uint8_t gas_range, const struct calib *cal)
+{
+	int64_t var1;
+	uint64_t var2;
+	int64_t var3;
+	const uint32_t lookup_table1[16] = {
+		2147483647u, 2147483647u, 2147483647u, 2147483647u, 2147483647u, 2126008810u, 2147483647u, 2130303777u,
+		2147483647u, 2147483647u, 2143188679u, 2136746228u, 2147483647u, 2126008810u, 2147483647u, 2147483647u
+	};
+	const uint32_t lookup_table2[16] = {
+		4096000000u, 2048000000u, 1024000000u, 512000000u, 255744255u, 127110228u, 64000000u, 32258064u,
+		16016016u, 8000000u, 4000000u, 2000000u, 1000000u, 500000u, 250000u, 125000u
+	};
+	var1 = (int64_t)((1340 + (5 * (int64_t)cal->range_sw_err)) * ((int64_t)lookup_table1[gas_range])) >> 16;
+	var2 = (((int64_t)((int64_t)gas_res_adc << 15) - (int64_t)(16777216)) + var1);
+	var3 = (((int64_t)lookup_table2[gas_range] * (int64_t)var1) >> 9);
+	return (uint32_t)((var3 + ((int64_t)var2 >> 1)) / (int64_t)var2);
+}
+
+static uint32_t calc_gas_resistance_high_int(uint16_t gas_res_adc, uint8_t gas_range)
+{
+	uint32_t var1 = UINT32_C(262144) >> gas_range;
+	int32_t var2 = (int32_t)gas_res_adc - INT32_C(512);
+	var2 *= INT32_C(3);
+	var2 = INT32_C(4096) + var2;
+	return ((UINT32_C(10000) * var1) / (uint32_t)var2) * 100;
+}
+
+static uint8_t calc_res_heat_int(uint16_t temp, const struct calib *cal)
+{
+	int32_t var1, var2, var3, var4, var5, heatr_res_x100;
+	if (temp > 400)
+		temp = 400;
+	var1 = (((int32_t)cal->amb_temp * cal->par_gh3) / 1000) * 256;
+	var2 = (cal->par_gh1 + 784) * (((((cal->par_gh2 + 154009) * temp * 5) / 100) + 3276800) / 10);
+	var3 = var1 + (var2 / 2);
+	var4 = (var3 / (cal->res_heat_range + 4));
+	var5 = (131 * cal->res_heat_val) + 65536;
+	heatr_res_x100 = (int32_t)(((var4 / var5) - 250) * 34);
+	return (uint8_t)((heatr_res_x100 + 50) / 100);
+}
+
+static uint8_t calc_gas_wait(uint16_t dur)
+{
+	uint8_t factor = 0;
+	if (dur >= 0xfc0)
+		return 0xff;
+	while (dur > 0x3F) {
+		dur = dur / 4;
+		factor += 1;
+	}
+	return (uint8_t)(dur + (factor * 64));
+}
+
+int main(void)
+{
+	static const uint32_t temps[] = {380000, 450000, 500000, 540000};
+	static const uint32_t press[] = {330000, 400000, 480000};
+	static const uint16_t hums[] = {12000, 20000, 28000};
+	static const uint16_t gases[] = {100, 512, 700, 1023};
+	static const uint16_t heatr_temps[] = {100, 200, 320, 400, 450};
+	static const int8_t ambients[] = {-10, 25, 40};
+	static const uint16_t durs[] = {0, 1, 63, 64, 100, 150, 255, 1000, 4031, 4032, 0xFFFF};
+	int first = 1;
+
+	printf("{\n  \"compensation\": [\n");
+	for (unsigned c = 0; c < sizeof(images) / sizeof(images[0]); c++) {
+		struct calib cal = {0};
+		parse(images[c], &cal);
+		for (unsigned t = 0; t < 4; t++)
+		for (unsigned p = 0; p < 3; p++)
+		for (unsigned h = 0; h < 3; h++) {
+			unsigned g = (t * 3 + p + h) % 4;
+			uint8_t gas_range = (uint8_t)((t * 9 + p * 3 + h + c) % 16);
+			float temp = calc_temperature(temps[t], &cal);
+			float pres = calc_pressure(press[p], &cal);
+			float hum = calc_humidity(hums[h], &cal);
+			int16_t temp_int = calc_temperature_int(temps[t], &cal);
+			uint32_t pres_int = calc_pressure_int(press[p], &cal);
+			uint32_t hum_int = calc_humidity_int(hums[h], &cal);
+
+			printf("%s    {\"calibration\": \"", first ? "" : ",\n");
+			for (int i = 0; i < 42; i++)
+				printf("%02x", images[c][i]);
+			printf("\", \"adc_temp\": %u, \"adc_pres\": %u, \"adc_hum\": %u, \"adc_gas\": %u, \"gas_range\": %u,\n",
+			       temps[t], press[p], hums[h], gases[g], gas_range);
+			printf("     \"t_fine\": %.9g, \"temperature\": %.9g, \"pressure\": %.9g, \"humidity\": %.9g,"
+			       " \"gas_low\": %.9g, \"gas_high\": %.9g,\n",
+			       cal.t_fine, temp, pres, hum,
+			       calc_gas_resistance_low(gases[g], gas_range, &cal), calc_gas_resistance_high(gases[g], gas_range));
+			printf("     \"t_fine_int\": %d, \"temperature_int\": %d, \"pressure_int\": %u, \"humidity_int\": %u,"
+			       " \"gas_low_int\": %u, \"gas_high_int\": %u}",
+			       cal.t_fine_int, temp_int, pres_int, hum_int,
+			       calc_gas_resistance_low_int(gases[g], gas_range, &cal), calc_gas_resistance_high_int(gases[g], gas_range));
+			first = 0;
+		}
+	}
+
+	printf("\n  ],\n  \"res_heat\": [\n");
+	first = 1;
+	for (unsigned c = 0; c < sizeof(images) / sizeof(images[0]); c++) {
+		struct calib cal = {0};
+		parse(images[c], &cal);
+		for (unsigned a = 0; a < 3; a++)
+		for (unsigned t = 0; t < 5; t++) {
+			cal.amb_temp = ambients[a];
+			printf("%s    {\"calibration\": \"", first ? "" : ",\n");
+			for (int i = 0; i < 42; i++)
+				printf("%02x", images[c][i]);
+			printf("\", \"ambient\": %d, \"target\": %u, \"res_heat\": %u, \"res_heat_int\": %u}",
+			       ambients[a], heatr_temps[t], calc_res_heat(heatr_temps[t], &cal), calc_res_heat_int(heatr_temps[t], &cal));
+			first = 0;
+		}
+	}
+
+	printf("\n  ],\n  \"gas_wait\": [\n");
+	for (unsigned d = 0; d < sizeof(durs) / sizeof(durs[0]); d++)
+		printf("%s    {\"dur\": %u, \"gas_wait\": %u}", d ? ",\n" : "", durs[d], calc_gas_wait(durs[d]));
+	printf("\n  ]\n}\n");
+
+	return 0;
+}
@@ -1,22 +1,26 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"machine"
+	"strings"
 	"time"
-    "BME68x/bme68x"
-    "strings"
+
+	"BME68x/bme68x"
+	"BME68x/bme68x/derived"
+	"BME68x/bme68x/gas"
+	"BME68x/bme68x/iaq"
 )
 
 func main() {
 
 	const seaLevelPressurehPa = 1013.25
-	const humidityDescription = "Comfortable"
 	machine.I2C1.Configure(machine.I2CConfig{
 		Frequency: 400 * machine.KHz,
-		SCL: machine.I2C1_SCL_PIN,
-		SDA: machine.I2C1_SDA_PIN,
+		SCL:       machine.I2C1_SCL_PIN,
+		SDA:       machine.I2C1_SDA_PIN,
 	})
 
 	time.Sleep(time.Second)
@@ -51,21 +55,41 @@ func main() {
 		return
 	}
 
+	// open estimate, not the Bosch BSEC IAQ
+	estimator := iaq.New(gas.New())
+
 	for {
-		if err := tsensor.Read(); err != nil {
+		m, err := tsensor.Measure()
+		if errors.Is(err, bme68x.ErrNoNewData) {
+			log.Print("no new data, retrying")
+
+			time.Sleep(2 * time.Second)
+			continue
+		} else if err != nil {
 			log.Fatal(fmt.Sprintf("Fatal reading sensor: %s", err))
 
 			time.Sleep(2 * time.Second)
 			continue
 		}
 
+		// the integer fields are also set when built with the bme68x_fixed tag
+		temp := float32(m.TemperatureMilli) / 1000
+		pres := float32(m.PressurePa)
+		hum := float32(m.HumidityMilli) / 1000
+
 		log.Print(strings.Repeat("-", 40))
 
-		log.Print(fmt.Sprintf("    Temperature: %.2f°C", tsensor.Temperature))
-		log.Print(fmt.Sprintf("    Pressure: %.fhPa", tsensor.Pressure/100))
-		log.Print(fmt.Sprintf("    Gas: %.1fKOhms", tsensor.GasResistance/1000))
-		log.Print(fmt.Sprintf("    Approx. Altitude: %.1fm", bme68x.CalcAltitude(seaLevelPressurehPa, tsensor.Pressure)))
-		log.Print(fmt.Sprintf("    Humidity: %.1f%% (%s)", tsensor.Humidity, humidityDescription))
+		log.Print(fmt.Sprintf("    Temperature: %.2f°C", temp))
+		log.Print(fmt.Sprintf("    Pressure: %.fhPa", pres/100))
+		log.Print(fmt.Sprintf("    Gas: %.1fKOhms", float32(m.GasResistanceOhms)/1000))
+		if estimate, err := estimator.Update(m); err != nil {
+			log.Print(fmt.Sprintf("    Air quality: n/a (%s)", err))
+		} else {
+			log.Print(fmt.Sprintf("    Air quality: %s", estimate))
+		}
+		log.Print(fmt.Sprintf("    Approx. Altitude: %.1fm", bme68x.CalcAltitude(seaLevelPressurehPa, pres)))
+		log.Print(fmt.Sprintf("    Humidity: %.1f%% (comfort: %s)", hum, derived.Classify(temp, hum)))
+		log.Print(fmt.Sprintf("    Dew point: %.1f°C", derived.DewPoint(temp, hum)))
 		log.Print(strings.Repeat("-", 40))
 
 		time.Sleep(2 * time.Second)